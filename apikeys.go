@@ -0,0 +1,337 @@
+package gotojs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// DefaultAPIKeyParam is the query parameter that may carry the api key instead of the header.
+const DefaultAPIKeyParam = "apikey"
+
+var typeOfAPIKey = reflect.TypeOf(&APIKey{})
+
+// APIKey represents a key that has been handed out to a partner. It will be injected whenever a
+// binding declares a parameter of type *APIKey. For calls without a key nil is injected.
+type APIKey struct {
+	Key   string `json:"key"`
+	Owner string `json:"owner"`
+
+	// Scopes contains patterns of the bindings the key may call like "Interface.Method" or
+	// "Interface.*". If empty, all bindings may be called.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Quota is the maximum amount of calls per QuotaPeriod. If 0, calls are not limited.
+	Quota uint64 `json:"quota,omitempty"`
+
+	// QuotaPeriod is the period the quota applies to. If 0, the quota applies to the lifetime of
+	// the key. It is encoded as duration string like "1h".
+	QuotaPeriod time.Duration `json:"quotaPeriod,omitempty"`
+
+	// Expires is the time the key expires. If zero, the key never expires.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// jsonAPIKey is used to encode an api key without its JSON methods.
+type jsonAPIKey APIKey
+
+// MarshalJSON encodes the key. The quota period is written as duration string like "1h".
+func (k *APIKey) MarshalJSON() ([]byte, error) {
+	var period string
+	if k.QuotaPeriod != 0 {
+		period = k.QuotaPeriod.String()
+	}
+	return json.Marshal(&struct {
+		*jsonAPIKey
+		QuotaPeriod string `json:"quotaPeriod,omitempty"`
+	}{(*jsonAPIKey)(k), period})
+}
+
+// UnmarshalJSON decodes the key. The quota period is expected as duration string like "1h".
+func (k *APIKey) UnmarshalJSON(raw []byte) error {
+	v := struct {
+		*jsonAPIKey
+		QuotaPeriod string `json:"quotaPeriod,omitempty"`
+	}{jsonAPIKey: (*jsonAPIKey)(k)}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+	k.QuotaPeriod = 0
+	if len(v.QuotaPeriod) > 0 {
+		d, err := time.ParseDuration(v.QuotaPeriod)
+		if err != nil {
+			return fmt.Errorf("Invalid quota period of api key of \"%s\": %s", k.Owner, err)
+		}
+		k.QuotaPeriod = d
+	}
+	return nil
+}
+
+// Expired returns true if the key has expired.
+func (k *APIKey) Expired() bool {
+	return !k.Expires.IsZero() && time.Now().After(k.Expires)
+}
+
+// Allows returns true if the key may call the binding of the given name.
+func (k *APIKey) Allows(name string) bool {
+	if len(k.Scopes) == 0 {
+		return true
+	}
+	for _, s := range k.Scopes {
+		if m, _ := path.Match(s, name); m {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyStore keeps the api keys and counts their usage.
+type APIKeyStore interface {
+	// Lookup returns the key or nil if the key is unknown.
+	Lookup(key string) *APIKey
+
+	// Use counts a call of the given key and returns the usage within the current quota period.
+	Use(k *APIKey) uint64
+
+	// Usage returns the usage of the given key within the current quota period.
+	Usage(key string) uint64
+}
+
+// keyUsage is the usage of a key within a quota period.
+type keyUsage struct {
+	count uint64
+	since time.Time
+}
+
+// MemoryAPIKeyStore is an in-memory implementation of APIKeyStore.
+type MemoryAPIKeyStore struct {
+	keys  map[string]*APIKey
+	usage map[string]*keyUsage
+	mutex sync.RWMutex
+}
+
+// NewMemoryAPIKeyStore creates a new store containing the given keys.
+func NewMemoryAPIKeyStore(keys ...*APIKey) *MemoryAPIKeyStore {
+	s := &MemoryAPIKeyStore{
+		keys:  make(map[string]*APIKey),
+		usage: make(map[string]*keyUsage)}
+	for _, k := range keys {
+		s.keys[k.Key] = k
+	}
+	return s
+}
+
+// Add adds or replaces a key.
+func (s *MemoryAPIKeyStore) Add(k *APIKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys[k.Key] = k
+}
+
+// Remove removes a key and its usage.
+func (s *MemoryAPIKeyStore) Remove(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.keys, key)
+	delete(s.usage, key)
+}
+
+// Keys returns all keys of the store.
+func (s *MemoryAPIKeyStore) Keys() []*APIKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	ret := make([]*APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		ret = append(ret, k)
+	}
+	return ret
+}
+
+// Lookup implements the APIKeyStore interface.
+func (s *MemoryAPIKeyStore) Lookup(key string) *APIKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.keys[key]
+}
+
+// Use implements the APIKeyStore interface.
+func (s *MemoryAPIKeyStore) Use(k *APIKey) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	u, found := s.usage[k.Key]
+	if !found || (k.QuotaPeriod > 0 && now.Sub(u.since) >= k.QuotaPeriod) {
+		u = &keyUsage{since: now}
+		s.usage[k.Key] = u
+	}
+	u.count++
+	return u.count
+}
+
+// Usage implements the APIKeyStore interface.
+func (s *MemoryAPIKeyStore) Usage(key string) uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	u, found := s.usage[key]
+	if !found {
+		return 0
+	}
+	if k := s.keys[key]; k != nil && k.QuotaPeriod > 0 && time.Since(u.since) >= k.QuotaPeriod {
+		return 0 // The quota period is over.
+	}
+	return u.count
+}
+
+// FileAPIKeyStore is an APIKeyStore whose keys are kept in a JSON file. The usage is kept in
+// memory only.
+type FileAPIKeyStore struct {
+	*MemoryAPIKeyStore
+	path string
+}
+
+// NewFileAPIKeyStore creates a store from the keys of the given JSON file. The file is created
+// once keys are added if it does not exist.
+func NewFileAPIKeyStore(path string) (*FileAPIKeyStore, error) {
+	s := &FileAPIKeyStore{
+		MemoryAPIKeyStore: NewMemoryAPIKeyStore(),
+		path:              path}
+	return s, s.Reload()
+}
+
+// Reload reads the keys from the file again. The usage of the remaining keys is kept.
+func (s *FileAPIKeyStore) Reload() error {
+	raw, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Could not read api keys: %s", err)
+	}
+
+	var keys []*APIKey
+	if err = json.Unmarshal(raw, &keys); err != nil {
+		return fmt.Errorf("Could not decode api keys: %s", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.keys = make(map[string]*APIKey)
+	for _, k := range keys {
+		s.keys[k.Key] = k
+	}
+	return nil
+}
+
+// save writes all keys to the file.
+func (s *FileAPIKeyStore) save() error {
+	raw, err := json.MarshalIndent(s.Keys(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, raw, 0600)
+}
+
+// Add adds or replaces a key and writes the file.
+func (s *FileAPIKeyStore) Add(k *APIKey) error {
+	s.MemoryAPIKeyStore.Add(k)
+	return s.save()
+}
+
+// Remove removes a key and writes the file.
+func (s *FileAPIKeyStore) Remove(key string) error {
+	s.MemoryAPIKeyStore.Remove(key)
+	return s.save()
+}
+
+// requestAPIKey extracts the api key from the header or the query parameter of the request.
+func requestAPIKey(r *http.Request) string {
+	if k := r.Header.Get(DefaultHeaderAPIKey); len(k) > 0 {
+		return k
+	}
+	return r.URL.Query().Get(DefaultAPIKeyParam)
+}
+
+// SetAPIKeyStore sets the store of the api keys. Calls carrying an api key are only accepted
+// if the key is found in the store.
+func (b *Container) SetAPIKeyStore(s APIKeyStore) {
+	b.apiKeys = s
+}
+
+// apiKey identifies the api key of the given call. Unknown or expired keys are answered with
+// status 401.
+func (b *Container) apiKey(hc *HTTPContext) *APIKey {
+	if b.apiKeys == nil {
+		return nil
+	}
+
+	key := requestAPIKey(hc.Request)
+	if len(key) == 0 {
+		return nil
+	}
+
+	k := b.apiKeys.Lookup(key)
+	if k == nil {
+		hc.Errorf(http.StatusUnauthorized, "Unknown api key.")
+	}
+	if k.Expired() {
+		hc.Errorf(http.StatusUnauthorized, "Api key of \"%s\" expired at %s.", k.Owner, k.Expires)
+	}
+	return k
+}
+
+// RequireAPIKey declares that the binding may only be called with a valid api key.
+func (b Binding) RequireAPIKey() Binding {
+	b.base().requireAPIKey = true
+	return b
+}
+
+// RequireAPIKey declares that the given bindings may only be called with a valid api key.
+func (bs Bindings) RequireAPIKey() Bindings {
+	for _, b := range bs {
+		b.RequireAPIKey()
+	}
+	return bs
+}
+
+// meter enforces the scopes and the quota of the api key of the call. The call is only counted
+// towards the quota once it has passed the filter chain, see use.
+func (b Binding) meter(hc *HTTPContext, injs Injections) {
+	bb := b.base()
+	k, _ := injs[typeOfAPIKey].(*APIKey)
+	if k == nil {
+		if bb.requireAPIKey {
+			hc.Errorf(http.StatusUnauthorized, "Api key required for \"%s\".", b.Name())
+		}
+		return
+	}
+
+	if !k.Allows(b.Name()) {
+		hc.Errorf(http.StatusForbidden, "Api key of \"%s\" is not allowed to call \"%s\".", k.Owner, b.Name())
+	}
+
+	if k.Quota > 0 && bb.container.apiKeys.Usage(k.Key) >= k.Quota {
+		hc.Errorf(http.StatusTooManyRequests, "Quota of api key of \"%s\" exceeded.", k.Owner)
+	}
+}
+
+// use counts an admitted call towards the quota of its api key. Concurrent calls exceeding the
+// quota are rejected.
+func (b Binding) use(inj Injections) {
+	store := b.base().container.apiKeys
+	k, _ := inj[typeOfAPIKey].(*APIKey)
+	if k == nil || store == nil {
+		return
+	}
+
+	if n := store.Use(k); k.Quota > 0 && n > k.Quota {
+		if hc, _ := inj[typeOfHTTPContext].(*HTTPContext); hc != nil && hc.Response != nil {
+			hc.Errorf(http.StatusTooManyRequests, "Quota of api key of \"%s\" exceeded.", k.Owner)
+		}
+		panic(fmt.Errorf("Quota of api key of \"%s\" exceeded.", k.Owner))
+	}
+}
@@ -0,0 +1,124 @@
+package gotojs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeys(t *testing.T) {
+	store := NewMemoryAPIKeyStore(
+		&APIKey{Key: "k1", Owner: "partner", Scopes: []string{"Partner.*"}, Quota: 2, QuotaPeriod: time.Hour},
+		&APIKey{Key: "k2", Owner: "old", Expires: time.Now().Add(-time.Minute)})
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.SetAPIKeyStore(store)
+	co.ExposeFunction(func(k *APIKey, s string) string { return k.Owner + s }, "Partner", "Echo").RequireAPIKey()
+	co.ExposeFunction(func() string { return "internal" }, "Internal", "Call")
+	h := co.Setup()
+
+	get := func(url, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		if len(key) > 0 {
+			req.Header.Set(DefaultHeaderAPIKey, key)
+		}
+		return record(h, req)
+	}
+
+	if r := get("/gotojs/Partner/Echo/x", ""); r.Code != http.StatusUnauthorized {
+		t.Errorf("Call without required key has not been rejected: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+
+	if r := get("/gotojs/Partner/Echo/x", "k1"); r.Body.String() != "\"partnerx\"" {
+		t.Errorf("Key has not been injected: %s", r.Body.String())
+	}
+
+	if r := get("/gotojs/Partner/Echo?x=y&"+DefaultAPIKeyParam+"=k1", ""); r.Body.String() != "\"partnery\"" {
+		t.Errorf("Key has not been taken from the query: %d %s", r.Code, r.Body.String())
+	}
+
+	if r := get("/gotojs/Partner/Echo/x", "k1"); r.Code != http.StatusTooManyRequests {
+		t.Errorf("Quota has not been enforced: %d/%d", r.Code, http.StatusTooManyRequests)
+	}
+
+	if r := get("/gotojs/Internal/Call", "k1"); r.Code != http.StatusForbidden {
+		t.Errorf("Scope has not been enforced: %d/%d", r.Code, http.StatusForbidden)
+	}
+
+	if r := get("/gotojs/Internal/Call", "k2"); r.Code != http.StatusUnauthorized {
+		t.Errorf("Expired key has been accepted: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+
+	if r := get("/gotojs/Internal/Call", "unknown"); r.Code != http.StatusUnauthorized {
+		t.Errorf("Unknown key has been accepted: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+
+	if u := store.Usage("k1"); u != 2 {
+		t.Errorf("Invalid usage count: %d/%d", u, 2)
+	}
+}
+
+func TestAPIKeyUsage(t *testing.T) {
+	store := NewMemoryAPIKeyStore(&APIKey{Key: "k1", Owner: "partner", Quota: 10})
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.SetAPIKeyStore(store)
+	co.ExposeFunction(func() string { return "limited" }, "Partner", "Limited").RateLimit(RateLimit{Rate: 0.001, Burst: 1, Key: APIKeyKey})
+	co.ExposeFunction(func() string { return "filtered" }, "Partner", "Filtered").If(func(b Binding, inj Injections) bool {
+		return false
+	})
+	h := co.Setup()
+
+	get := func(url string) int {
+		req := httptest.NewRequest("GET", url, nil)
+		req.Header.Set(DefaultHeaderAPIKey, "k1")
+		return record(h, req).Code
+	}
+
+	get("/gotojs/Partner/Limited")
+	if c := get("/gotojs/Partner/Limited"); c != http.StatusTooManyRequests {
+		t.Errorf("Call exceeding the rate limit has not been rejected: %d", c)
+	}
+	get("/gotojs/Partner/Filtered")
+	if u := store.Usage("k1"); u != 1 {
+		t.Errorf("Rejected calls have been counted: %d/%d", u, 1)
+	}
+}
+
+func TestFileAPIKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotojs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys.json")
+
+	s, err := NewFileAPIKeyStore(path)
+	if err != nil {
+		t.Fatalf("Could not create store: %s", err)
+	}
+	if err := s.Add(&APIKey{Key: "k1", Owner: "partner", Scopes: []string{"A.*"}, QuotaPeriod: time.Hour}); err != nil {
+		t.Fatalf("Could not add key: %s", err)
+	}
+
+	if raw, _ := ioutil.ReadFile(path); !strings.Contains(string(raw), `"quotaPeriod": "1h0m0s"`) {
+		t.Errorf("Quota period has not been written as duration: %s", raw)
+	}
+
+	s, err = NewFileAPIKeyStore(path)
+	if err != nil {
+		t.Fatalf("Could not load store: %s", err)
+	}
+	if k := s.Lookup("k1"); k == nil || k.Owner != "partner" || !k.Allows("A.B") || k.Allows("B.A") || k.QuotaPeriod != time.Hour {
+		t.Errorf("Key has not been persisted: %v", k)
+	}
+
+	s.Remove("k1")
+	s.Reload()
+	if s.Lookup("k1") != nil {
+		t.Errorf("Key has not been removed.")
+	}
+}
@@ -0,0 +1,274 @@
+package gotojs
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/sebkl/gotojs/client"
+)
+
+// Default values of the authentication.
+const (
+	DefaultRealm       = "gotojs"
+	DefaultHMACMaxSkew = 5 * time.Minute
+)
+
+// Principal represents an authenticated caller. It will be injected whenever a binding
+// declares a parameter of type *Principal. For calls without credentials nil is injected.
+type Principal struct {
+	Name   string
+	Roles  []string
+	Claims Claims
+}
+
+// HasRole returns true if the principal has the given role.
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && ContainsS(p.Roles, role)
+}
+
+// Authenticator authenticates incoming calls. If a call does not carry any credentials the
+// authenticator is responsible for, nil and no error are returned. Invalid credentials result
+// in an error.
+type Authenticator interface {
+	Authenticate(hc *HTTPContext) (*Principal, error)
+}
+
+// Challenger may be implemented by an Authenticator in order to provide a WWW-Authenticate
+// challenge for unauthenticated calls.
+type Challenger interface {
+	Challenge() string
+}
+
+// AuthenticatorFunc is a function which implements the Authenticator interface.
+type AuthenticatorFunc func(hc *HTTPContext) (*Principal, error)
+
+// Authenticate implements the Authenticator interface.
+func (f AuthenticatorFunc) Authenticate(hc *HTTPContext) (*Principal, error) { return f(hc) }
+
+// BasicAuthenticator authenticates calls by HTTP Basic authentication.
+type BasicAuthenticator struct {
+	Realm string
+
+	// Verify returns the principal of valid credentials or nil.
+	Verify func(username, password string) *Principal
+}
+
+// Authenticate implements the Authenticator interface.
+func (a *BasicAuthenticator) Authenticate(hc *HTTPContext) (*Principal, error) {
+	username, password, ok := hc.Request.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	if p := a.Verify(username, password); p != nil {
+		return p, nil
+	}
+	return nil, fmt.Errorf("Invalid credentials for user \"%s\".", username)
+}
+
+// Challenge implements the Challenger interface.
+func (a *BasicAuthenticator) Challenge() string {
+	realm := a.Realm
+	if len(realm) == 0 {
+		realm = DefaultRealm
+	}
+	return fmt.Sprintf("Basic realm=\"%s\"", realm)
+}
+
+// bearerToken extracts a bearer token from the authorization header.
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get(HeaderAuthorization)
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:]), true
+	}
+	return "", false
+}
+
+// BearerAuthenticator authenticates calls by a bearer token.
+type BearerAuthenticator struct {
+	// Verify returns the principal of a valid token or nil.
+	Verify func(token string) *Principal
+}
+
+// Authenticate implements the Authenticator interface.
+func (a *BearerAuthenticator) Authenticate(hc *HTTPContext) (*Principal, error) {
+	token, ok := bearerToken(hc.Request)
+	if !ok {
+		return nil, nil
+	}
+	if p := a.Verify(token); p != nil {
+		return p, nil
+	}
+	return nil, fmt.Errorf("Invalid bearer token.")
+}
+
+// Challenge implements the Challenger interface.
+func (a *BearerAuthenticator) Challenge() string { return "Bearer" }
+
+// HMACAuthenticator authenticates calls that have been signed by a shared secret. See
+// SignRequest of the client package for the signing side. Each signed request carries a nonce
+// which is remembered as long as the signature date is accepted, so captured requests cannot
+// be replayed. The nonces are kept in memory, so replays are only detected by the instance
+// that received the original request.
+type HMACAuthenticator struct {
+	// Secret returns the secret of the given key id or nil if the key is unknown.
+	Secret func(keyID string) []byte
+
+	// Principal returns the principal of the given key id. If nil, a principal named by the
+	// key id is created.
+	Principal func(keyID string) *Principal
+
+	// MaxSkew is the maximum accepted difference of the signed date. DefaultHMACMaxSkew if 0.
+	MaxSkew time.Duration
+
+	nonces map[string]time.Time
+	swept  time.Time
+	mutex  sync.Mutex
+}
+
+// Authenticate implements the Authenticator interface.
+func (a *HMACAuthenticator) Authenticate(hc *HTTPContext) (*Principal, error) {
+	r := hc.Request
+	h := r.Header.Get(HeaderAuthorization)
+	if !strings.HasPrefix(h, HMACScheme+" ") {
+		return nil, nil
+	}
+
+	cred := strings.SplitN(strings.TrimSpace(h[len(HMACScheme)+1:]), ":", 2)
+	if len(cred) != 2 {
+		return nil, fmt.Errorf("Malformed signature.")
+	}
+
+	secret := a.Secret(cred[0])
+	if secret == nil {
+		return nil, fmt.Errorf("Unknown key \"%s\".", cred[0])
+	}
+
+	d, err := time.Parse(time.RFC1123, r.Header.Get(HeaderDate))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid signature date: %s", err)
+	}
+
+	skew := a.MaxSkew
+	if skew <= 0 {
+		skew = DefaultHMACMaxSkew
+	}
+	if diff := time.Since(d); diff > skew || diff < -skew {
+		return nil, fmt.Errorf("Signature date exceeds the accepted skew.")
+	}
+
+	sig, err := Signature(r, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal([]byte(sig), []byte(cred[1])) {
+		return nil, fmt.Errorf("Invalid signature.")
+	}
+
+	nonce := r.Header.Get(HeaderNonce)
+	if len(nonce) == 0 {
+		return nil, fmt.Errorf("Signature nonce missing.")
+	}
+	if a.seen(cred[0]+":"+nonce, d.Add(skew), skew) {
+		return nil, fmt.Errorf("Signed request has already been received.")
+	}
+
+	if a.Principal != nil {
+		if p := a.Principal(cred[0]); p != nil {
+			return p, nil
+		}
+		return nil, fmt.Errorf("No principal for key \"%s\".", cred[0])
+	}
+	return &Principal{Name: cred[0]}, nil
+}
+
+// seen remembers the nonce until it expires and returns true if it has already been remembered.
+// Expired nonces are swept once per skew.
+func (a *HMACAuthenticator) seen(nonce string, expires time.Time, skew time.Duration) bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	if a.nonces == nil {
+		a.nonces, a.swept = make(map[string]time.Time), now
+	}
+	if now.Sub(a.swept) > skew {
+		for n, e := range a.nonces {
+			if now.After(e) {
+				delete(a.nonces, n)
+			}
+		}
+		a.swept = now
+	}
+
+	if _, found := a.nonces[nonce]; found {
+		return true
+	}
+	a.nonces[nonce] = expires
+	return false
+}
+
+// Challenge implements the Challenger interface.
+func (a *HMACAuthenticator) Challenge() string { return HMACScheme }
+
+// Authenticate adds authenticators to the container. Incoming calls are authenticated by the
+// first authenticator that finds credentials in the request.
+func (b *Container) Authenticate(a ...Authenticator) {
+	b.authenticators = append(b.authenticators, a...)
+}
+
+// authenticate identifies the principal of the given call. Invalid credentials are answered
+// with status 401.
+func (b *Container) authenticate(hc *HTTPContext) *Principal {
+	for _, a := range b.authenticators {
+		p, err := a.Authenticate(hc)
+		if err != nil {
+			b.challenge(hc)
+			hc.Errorf(http.StatusUnauthorized, "Authentication failed: %s", err)
+		}
+		if p != nil {
+			return p
+		}
+	}
+	return nil
+}
+
+// challenge sets the WWW-Authenticate header for all authenticators that provide a challenge.
+func (b *Container) challenge(hc *HTTPContext) {
+	for _, a := range b.authenticators {
+		if c, ok := a.(Challenger); ok {
+			hc.Response.Header().Add("WWW-Authenticate", c.Challenge())
+		}
+	}
+}
+
+// Protect declares that the binding may only be called by an authenticated principal.
+// Unauthenticated calls are answered with status 401.
+func (b Binding) Protect() Binding {
+	b.base().protected = true
+	return b
+}
+
+// Protect declares that the given bindings may only be called by an authenticated principal.
+func (bs Bindings) Protect() Bindings {
+	for _, b := range bs {
+		b.Protect()
+	}
+	return bs
+}
+
+// IsProtected returns true if the binding requires an authenticated principal.
+func (b Binding) IsProtected() bool {
+	return b.base().protected
+}
+
+// authorize enforces that protected bindings are only called by an authenticated principal.
+func (b Binding) authorize(hc *HTTPContext) {
+	if b.IsProtected() && hc.Principal == nil {
+		b.base().container.challenge(hc)
+		hc.Errorf(http.StatusUnauthorized, "Authentication required for \"%s\".", b.Name())
+	}
+}
@@ -0,0 +1,115 @@
+package gotojs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/sebkl/gotojs/client"
+)
+
+func authContainer() *Container {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.Authenticate(
+		&BasicAuthenticator{Verify: func(u, p string) *Principal {
+			if u == "alice" && p == "secret" {
+				return &Principal{Name: u, Roles: []string{"admin"}}
+			}
+			return nil
+		}},
+		&BearerAuthenticator{Verify: func(token string) *Principal {
+			if token == "T0KEN" {
+				return &Principal{Name: "bob"}
+			}
+			return nil
+		}},
+		&HMACAuthenticator{Secret: func(keyID string) []byte {
+			if keyID == "k1" {
+				return []byte("shared")
+			}
+			return nil
+		}})
+	co.ExposeFunction(func(p *Principal) string {
+		if p == nil {
+			return "anonymous"
+		}
+		return p.Name
+	}, "Auth", "Who")
+	co.ExposeFunction(func(p *Principal) string { return p.Name }, "Auth", "Secret").Protect()
+	return co
+}
+
+func TestAuthenticate(t *testing.T) {
+	h := authContainer().Setup()
+
+	if r := record(h, httptest.NewRequest("GET", "/gotojs/Auth/Who", nil)); r.Body.String() != "\"anonymous\"" {
+		t.Errorf("Anonymous principal is not nil: %s", r.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/gotojs/Auth/Secret", nil)
+	req.SetBasicAuth("alice", "secret")
+	if r := record(h, req); r.Code != http.StatusOK || r.Body.String() != "\"alice\"" {
+		t.Errorf("Basic authentication failed: %d %s", r.Code, r.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/gotojs/Auth/Secret", nil)
+	req.Header.Set("Authorization", "Bearer T0KEN")
+	if r := record(h, req); r.Code != http.StatusOK || r.Body.String() != "\"bob\"" {
+		t.Errorf("Bearer authentication failed: %d %s", r.Code, r.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/gotojs/Auth/Secret", bytes.NewBufferString("[]"))
+	req.Header.Set(CTHeader, DefaultMimeType)
+	if err := SignRequest(req, "k1", []byte("shared")); err != nil {
+		t.Fatalf("Signing failed: %s", err)
+	}
+	if r := record(h, req); r.Code != http.StatusOK || r.Body.String() != "\"k1\"" {
+		t.Errorf("HMAC authentication failed: %d %s", r.Code, r.Body.String())
+	}
+}
+
+func TestAuthenticateRejected(t *testing.T) {
+	h := authContainer().Setup()
+
+	r := record(h, httptest.NewRequest("GET", "/gotojs/Auth/Secret", nil))
+	if r.Code != http.StatusUnauthorized {
+		t.Errorf("Unauthenticated call has not been rejected: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+	if len(r.Header()["Www-Authenticate"]) != 3 {
+		t.Errorf("Invalid challenges: %v", r.Header()["Www-Authenticate"])
+	}
+
+	req := httptest.NewRequest("GET", "/gotojs/Auth/Who", nil)
+	req.SetBasicAuth("alice", "wrong")
+	if r := record(h, req); r.Code != http.StatusUnauthorized {
+		t.Errorf("Invalid credentials have not been rejected: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest("POST", "/gotojs/Auth/Secret", bytes.NewBufferString("[]"))
+	req.Header.Set(CTHeader, DefaultMimeType)
+	SignRequest(req, "k1", []byte("shared"))
+	req.Body = http.NoBody
+	if r := record(h, req); r.Code != http.StatusUnauthorized {
+		t.Errorf("Tampered body has not been rejected: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+
+	signed := func(header http.Header) *http.Request {
+		req := httptest.NewRequest("POST", "/gotojs/Auth/Secret", bytes.NewBufferString("[]"))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		if header == nil {
+			SignRequest(req, "k1", []byte("shared"))
+		} else {
+			req.Header = header
+		}
+		return req
+	}
+	req = signed(nil)
+	header := req.Header.Clone()
+	if r := record(h, req); r.Code != http.StatusOK {
+		t.Errorf("Signed request has been rejected: %d/%d", r.Code, http.StatusOK)
+	}
+	if r := record(h, signed(header)); r.Code != http.StatusUnauthorized {
+		t.Errorf("Replayed request has not been rejected: %d/%d", r.Code, http.StatusUnauthorized)
+	}
+}
@@ -8,9 +8,11 @@ import (
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 )
 
 const (
@@ -48,6 +50,8 @@ type bindingContainer map[string]Interface
 type Container struct {
 	bindingContainer
 	globalInjections       Injections
+	providers              map[reflect.Type]*provider
+	builtinInjections      map[reflect.Type]bool
 	revision               uint64
 	converterRegistry      map[reflect.Type]Converter
 	*http.ServeMux         //embed http muxer
@@ -64,7 +68,30 @@ type Container struct {
 	publicDir              string
 	publicContext          string
 	fileServer             http.Handler
-	key                    []byte //key used to encrypt the cookie.
+	key                    []byte   //key used to encrypt the cookie.
+	keys                   [][]byte //keys used to decrypt the cookie, the first one equals key.
+	sessionTTL             time.Duration
+	sessionStore           SessionStore
+	cookie                 *CookiePolicy
+	sessionType            reflect.Type
+	jobs                   *jobQueue
+	responseCache          *responseCache
+	idempotency            *idempotencyStore
+	rateLimitStore         RateLimitStore
+	authenticators         []Authenticator
+	interfaceRoles         map[string][]string
+	interceptors           []Interceptor
+	filters                []Filter
+	interfaceFilters       map[string][]Filter
+	modules                []Module
+	modulesStarted         bool
+	mounts                 []*mount
+	interfaceInterceptors  map[string][]Interceptor
+	apiKeys                APIKeyStore
+	cors                   *CORSPolicy
+	csrf                   bool
+	csrfOrigins            []string
+	routes                 []*Route
 	HTTPContextConstructor HTTPContextConstructor
 }
 
@@ -111,19 +138,54 @@ func (b Container) RegisterConverter(t interface{}, c Converter) {
 // default singleton which will be injected in case no further object of this type will is
 // provided for InvokeI calls.
 func (b Binding) AddInjection(i interface{}) Binding {
-	bb := b.base()
-	it := reflect.TypeOf(i)
-	bb.singletons[it] = i
+	b.S().AddInjection(i)
+	return b
+}
 
-	pta := parameterTypeArray(b, true)
+// declareInjection declares the parameters of the binding that receive objects of the given type.
+func (b Binding) declareInjection(it reflect.Type) {
+	b.base().injections = b.resolveInjection(it)
+}
 
-	for ii, t := range pta {
-		if t == it {
-			bb.injections[ii] = it
+// resolveInjection returns the injected parameters of the binding after declaring the given type
+// without modifying the binding. These are parameters of the very same type and parameters of a
+// non-empty interface type the given type implements. The built-in injections of the container
+// like *HTTPContext are only injected by their exact type. A parameter matching multiple types by
+// interface is ambiguous and causes a panic unless one of the types matches exactly.
+func (b Binding) resolveInjection(it reflect.Type) map[int]reflect.Type {
+	bb := b.base()
+	ret := make(map[int]reflect.Type, len(bb.injections)+1)
+	for ii, t := range bb.injections {
+		ret[ii] = t
+	}
+
+	byInterface := !bb.container.builtinInjections[it]
+	for ii, t := range parameterTypeArray(b, true) {
+		switch prev, found := ret[ii]; {
+		case t == it:
+			ret[ii] = it
+		case !byInterface || t.Kind() != reflect.Interface || t.NumMethod() == 0 || !it.Implements(t):
+		case !found:
+			ret[ii] = it
+		case prev != it && prev != t:
+			names := []string{prev.String(), it.String()}
+			sort.Strings(names)
+			panic(fmt.Errorf("Ambiguous injection for parameter %d of type \"%s\" of binding \"%s\": %s", ii, t, b.Name(), strings.Join(names, ", ")))
 		}
 	}
+	return ret
+}
 
-	return b
+// declareInjection declares the given type as injection of all bindings. Ambiguities are
+// detected before any of the bindings is modified.
+func (bs Bindings) declareInjection(it reflect.Type) {
+	resolved := make([]map[int]reflect.Type, len(bs))
+	for i, b := range bs {
+		resolved[i] = b.resolveInjection(it)
+	}
+	for i, b := range bs {
+		b.base().injections = resolved[i]
+	}
 }
 
 //S method returns an one element array of this binding.
@@ -177,10 +239,13 @@ func countParameterType(b bindingInterface, i interface{}) (ret int) {
 	return
 }
 
-// AddInjection is a convenience method to AddInjection of type Binding.
+// AddInjection is a convenience method to AddInjection of type Binding. None of the bindings
+// is modified if the injection is ambiguous for any of them.
 func (bs Bindings) AddInjection(i interface{}) Bindings {
+	it := reflect.TypeOf(i)
+	bs.declareInjection(it)
 	for _, b := range bs {
-		b.AddInjection(i)
+		b.base().singletons[it] = i
 	}
 	return bs
 }
@@ -188,9 +253,8 @@ func (bs Bindings) AddInjection(i interface{}) Bindings {
 // SetupGlobaleIjection declares a type that will always be injected.
 // This applies for both existing bindings as well as new bindings.
 func (b Container) SetupGlobalInjection(i interface{}) {
-	t := reflect.TypeOf(i)
-	b.globalInjections[t] = i
 	b.Bindings().AddInjection(i) // Add Injection for all existing bindings.
+	b.globalInjections[reflect.TypeOf(i)] = i
 }
 
 // Match filters the list of Bindings and only returns those bindings whose
@@ -217,11 +281,12 @@ func (bs Bindings) Match(pattern string) Bindings {
 	return ret[0:i]
 }
 
-// addGlobalInjection adds the global injection types to the given binding.
+// addGlobalInjection adds the global injection types and the provided types to the given binding.
 func (b Binding) addGlobalInjections() {
 	for _, v := range b.base().container.globalInjections {
 		b.AddInjection(v)
 	}
+	b.addProviders()
 }
 
 // Expose an entire interface. All methods of the given interface will be exposed. THe name of the
@@ -382,19 +447,42 @@ func (b *Container) ExposeYourself(args ...string) (ret Bindings) {
 	if len(args) > 0 {
 		in = args[0]
 	}
-	ret = make(Bindings, 2)
-	ret[0] = b.ExposeFunction(func(b *Container) map[string]string {
+	ret = make(Bindings, 3)
+	ret[0] = b.ExposeFunction(func(b *Container, hc *HTTPContext) map[string]string {
 		bs := b.Bindings()
+		allowed := b.permitted(hc)
 		ret := make(map[string]string)
 		for _, b := range bs {
-			ret[b.Name()] = b.ValidationString()
+			if allowed(b) {
+				ret[b.Name()] = b.ValidationString()
+			}
 		}
 		return ret
 	}, in, "Bindings").AddInjection(b)[0]
 
-	ret[1] = b.ExposeFunction(func(b *Container) []string {
-		return b.InterfaceNames()
+	ret[1] = b.ExposeFunction(func(b *Container, hc *HTTPContext) (ret []string) {
+		allowed := b.permitted(hc)
+		for _, in := range b.InterfaceNames() {
+			for _, bi := range b.bindingContainer[in] {
+				if allowed(bi) {
+					ret = append(ret, in)
+					break
+				}
+			}
+		}
+		return
 	}, in, "Interfaces").AddInjection(b)[0]
+
+	ret[2] = b.ExposeFunction(func(b *Container, hc *HTTPContext) map[string]string {
+		allowed := b.permitted(hc)
+		ret := make(map[string]string)
+		for _, rt := range b.Routes() {
+			if allowed(rt.Binding) {
+				ret[rt.String()] = rt.Binding.Name()
+			}
+		}
+		return ret
+	}, in, "Routes").AddInjection(b)[0]
 	return
 }
 
@@ -405,7 +493,8 @@ func (b Binding) If(f Filter) Binding {
 	return b
 }
 
-// ClearFilter removes all filters for the given binding.
+// ClearFilter removes all filters for the given binding. The filters of the container and the
+// interface still apply, see Exempt.
 func (b Binding) ClearFilter() Binding {
 	b.base().filters = make([]Filter, 0)
 	return b
@@ -425,7 +514,8 @@ func (bs Bindings) If(f Filter) Bindings {
 	return bs
 }
 
-// ClearFilter remove all filters from the given bindings.
+// ClearFilter remove all filters from the given bindings. The filters of the container and the
+// interfaces still apply, see Exempt.
 func (bs Bindings) ClearFilter() Bindings {
 	for _, b := range bs {
 		b.ClearFilter()
@@ -631,6 +721,14 @@ func (b *Container) convertParameterValue(av reflect.Value, at reflect.Type) ref
 			v, err = strconv.ParseInt(skv, 10, 64)
 		case reflect.String:
 			return av
+		case reflect.Bool:
+			v, err = strconv.ParseBool(skv)
+		case reflect.Struct, reflect.Map, reflect.Slice:
+			//Complex parameters of GET calls are passed as JSON string.
+			rv := reflect.New(at)
+			if err = json.Unmarshal([]byte(skv), rv.Interface()); err == nil {
+				return rv.Elem()
+			}
 		default:
 			err = fmt.Errorf("No conversion type found for %s", tk)
 		}
@@ -677,8 +775,8 @@ func callValuesI(b bindingInterface, inj Injections, args []interface{}) (ret []
 		var av reflect.Value
 
 		// Check if this parameter needs to be injected
-		if _, ok := b.base().injections[ai]; ok {
-			if in, ok := inj[at]; ok { // a object of type at is provided by InvokeI call
+		if it, ok := b.base().injections[ai]; ok {
+			if in, ok := inj[it]; ok { // a object of type it is provided by InvokeI call
 				av = reflect.ValueOf(in).Convert(at)
 			} else {
 				panic(fmt.Errorf("Injection for type \"%s\" not found.", at))
@@ -717,17 +815,43 @@ func convertReturnValue(iret []reflect.Value) interface{} {
 	}
 }
 
-//InvokeI invokes the given binding and adds the binding itself as an injection.
-func (b Binding) InvokeI(ri Injections, args ...interface{}) interface{} {
-	//Merge Injections. Runtime objects overwrite singletons.
-	inj := MergeInjections(b.base().singletons, ri, NewI(&b))
+// mergeInjections compiles the final injections of a call. Runtime objects overwrite singletons
+// and the binding itself is added.
+func (b Binding) mergeInjections(ri Injections) Injections {
+	return MergeInjections(b.base().singletons, ri, NewI(&b))
+}
+
+// filter executes the filter chain of the binding. It returns false if one of the filters
+// aborted the chain. A rejection of a HTTP call is answered with its status. Calls passing the
+// chain are counted towards the quota of their api key.
+func (b Binding) filter(inj Injections) bool {
+	defer func() {
+		if re := recover(); re != nil {
+			if r, ok := re.(*Rejection); ok {
+				if hc, _ := inj[typeOfHTTPContext].(*HTTPContext); hc != nil && hc.Response != nil {
+					hc.Errorf(r.Status, "%s", r.Message)
+				}
+			}
+			panic(re)
+		}
+	}()
 
-	//Execute filters
-	for _, f := range b.base().filters {
+	for _, f := range b.Filters() {
 		if !f(Binding{b}, inj) {
-			return nil
+			return false
 		}
 	}
+	b.use(inj)
+	return true
+}
+
+//InvokeI invokes the given binding and adds the binding itself as an injection.
+func (b Binding) InvokeI(ri Injections, args ...interface{}) interface{} {
+	inj := b.mergeInjections(ri)
+
+	if !b.filter(inj) {
+		return nil
+	}
 
-	return b.invokeI(inj, args)
+	return b.invoke(inj, args)
 }
@@ -1,7 +1,11 @@
 package gotojs
 
 import (
+	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -230,6 +234,70 @@ func TestDynamicInjectionSimilarParameter(t *testing.T) {
 	}
 }
 
+type testStore interface {
+	Name() string
+}
+
+type fileTestStore struct{ path string }
+
+func (s *fileTestStore) Name() string { return s.path }
+
+type memTestStore struct{}
+
+func (s *memTestStore) Name() string { return "mem" }
+
+func TestInterfaceInjection(t *testing.T) {
+	co := NewContainer()
+	co.SetupGlobalInjection(&fileTestStore{path: "default"})
+	co.ExposeFunction(func(s testStore, p string) string { return s.Name() + p }, "Store", "Name")
+
+	if res := co.InvokeI("Store", "Name", nil, "!"); res != "default!" {
+		t.Errorf("Singleton has not been injected by interface: %s", res)
+	}
+
+	if res := co.InvokeI("Store", "Name", NewI(&fileTestStore{path: "given"}), "!"); res != "given!" {
+		t.Errorf("Runtime object has not been injected by interface: %s", res)
+	}
+
+	rejected := func(f func()) {
+		defer func() {
+			if re := recover(); re == nil {
+				t.Errorf("Ambiguous injection has not been rejected.")
+			} else if !strings.Contains(fmt.Sprint(re), "*gotojs.fileTestStore, *gotojs.memTestStore") {
+				t.Errorf("Unexpected ambiguity error: %s", re)
+			}
+		}()
+		f()
+	}
+	rejected(func() { co.SetupGlobalInjection(&memTestStore{}) })
+
+	// The container is still usable after the rejected registration.
+	co.ExposeFunction(func(s testStore) string { return s.Name() }, "Store", "Other")
+	if res := co.Invoke("Store", "Other"); res != "default" {
+		t.Errorf("Rejected injection has been registered: %s", res)
+	}
+
+	b, _ := co.Binding("Store", "Name")
+	rejected(func() { b.AddInjection(&memTestStore{}) })
+	if res := co.Invoke("Store", "Name", "?"); res != "default?" {
+		t.Errorf("Rejected singleton has been registered: %s", res)
+	}
+
+	co = NewContainer()
+	co.SetupGlobalInjection(&fileTestStore{})
+	co.SetupGlobalInjection(&memTestStore{})
+	rejected(func() {
+		co.ExposeFunction(func(s testStore) string { return s.Name() }, "Store", "Ambiguous")
+	})
+	if _, found := co.Binding("Store", "Ambiguous"); found || ContainsS(co.InterfaceNames(), "Store") {
+		t.Errorf("Rejected binding has been registered.")
+	}
+	co.ExposeFunction(func() string { return "ok" }, "Other", "Name")
+	if res := co.Invoke("Other", "Name"); res != "ok" || len(co.Bindings()) != 1 {
+		t.Errorf("Container is not usable after a rejected binding: %v", res)
+	}
+}
+
 func TestInterfaceRemoval(t *testing.T) {
 	be.RemoveInterface("IService")
 	if ContainsS(be.InterfaceNames(), "IService") {
@@ -257,6 +325,61 @@ func TestSimpleFilterChain(t *testing.T) {
 	}
 }
 
+func TestContainerFilters(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	var chain []string
+	co.If(func(b Binding, inj Injections) bool { chain = append(chain, "container"); return true })
+	co.IfInterface("Admin", func(b Binding, inj Injections) bool {
+		chain = append(chain, "interface")
+		if hc := inj[typeOfHTTPContext].(*HTTPContext); hc.Request != nil && hc.Request.Header.Get("x-admin") != "yes" {
+			return Reject(http.StatusForbidden, "Admins only.")
+		}
+		return true
+	})
+
+	// Exposed after the filters have been added.
+	co.ExposeFunction(func() string { return "ok" }, "Admin", "Reset").If(func(b Binding, inj Injections) bool {
+		chain = append(chain, "binding")
+		return true
+	})
+	co.ExposeFunction(func() string { return "ok" }, "Public", "Ping")
+
+	if co.Invoke("Admin", "Reset") != "ok" || strings.Join(chain, ",") != "container,interface,binding" {
+		t.Errorf("Unexpected filter chain: %v", chain)
+	}
+
+	chain = nil
+	if co.Invoke("Public", "Ping") != "ok" || strings.Join(chain, ",") != "container" {
+		t.Errorf("Interface filter applied to foreign interface: %v", chain)
+	}
+
+	h := co.Setup()
+	req := httptest.NewRequest("POST", "/gotojs/Admin/Reset", strings.NewReader("[]"))
+	req.Header.Set(CTHeader, DefaultMimeType)
+	if r := record(h, req); r.Code != http.StatusForbidden || !strings.Contains(r.Body.String(), "Admins only.") {
+		t.Errorf("Rejected call not answered by its status: %d %s", r.Code, r.Body.String())
+	}
+
+	b, _ := co.Binding("Admin", "Reset")
+	chain = nil
+	if b.Exempt().Invoke() != "ok" || strings.Join(chain, ",") != "binding" {
+		t.Errorf("Exempt binding has been filtered: %v", chain)
+	}
+
+	co.ClearInterfaceFilters("Admin")
+	co.ExposeFunction(func() string { return "ok" }, "Admin", "Status")
+	chain = nil
+	if co.Invoke("Admin", "Status") != "ok" || strings.Join(chain, ",") != "container" {
+		t.Errorf("Interface filters have not been cleared: %v", chain)
+	}
+
+	co.ClearFilters()
+	chain = nil
+	if co.Invoke("Public", "Ping") != "ok" || len(chain) > 0 {
+		t.Errorf("Container filters have not been cleared: %v", chain)
+	}
+}
+
 func TestSingletonInjection(t *testing.T) {
 	type TestType struct {
 		val int
@@ -26,7 +26,19 @@ type binding struct {
 	injections    map[int]reflect.Type
 	singletons    Injections
 	filters       []Filter
+	exempt        bool
 	container     *Container
+	async         bool
+	cachePolicy   *CachePolicy
+	coalescer     *coalescer
+	rateLimiters  []*rateLimiter
+	protected     bool
+	roles         []string
+	requireAPIKey bool
+	safe          bool
+	requireCSRF   bool
+	methods       []string
+	interceptors  []Interceptor
 }
 
 type functionBinding struct {
@@ -139,26 +151,15 @@ func (b *Container) newRemoteBinding(i remoteBinder, sig, in, mn string) (ret Bi
 		i:               i,
 	}}
 	ret.addGlobalInjections()
-	b.bindingContainer[in][mn] = ret
+	b.addBinding(ret)
 	return
 }
 
 // newBinding creates a new binding object that is associated with the given container.
-// All existing global Injections will be added to this binding.
+// The binding is registered by addBinding once it has been set up completely.
 func (b *Container) newBinding(in, mn string) *binding {
-	_, found := b.Binding(in, mn)
-	if found {
+	if _, found := b.Binding(in, mn); found {
 		log.Printf("Binding \"%s\" already exposed for interface \"%s\". Overwriting.", mn, in)
-	} else {
-		if _, f := b.bindingContainer[in]; !f {
-			im := make(map[string]Binding)
-			b.bindingContainer[in] = im
-		}
-
-		if _, f := b.bindingContainer[in][mn]; !f {
-			r := Binding{}
-			b.bindingContainer[in][mn] = r
-		}
 	}
 	p := &binding{
 		elemName:      mn,
@@ -169,6 +170,17 @@ func (b *Container) newBinding(in, mn string) *binding {
 	return p
 }
 
+// addBinding registers the binding at the container. It panics if the interface name collides
+// with a mounted container.
+func (b *Container) addBinding(bi Binding) {
+	in, mn := bi.base().interfaceName, bi.base().elemName
+	b.checkMountCollision(in)
+	if _, f := b.bindingContainer[in]; !f {
+		b.bindingContainer[in] = make(map[string]Binding)
+	}
+	b.bindingContainer[in][mn] = bi
+}
+
 func (b *Container) newHandlerBinding(handler http.Handler, in, mn string) (ret Binding) {
 	ret = Binding{
 		bindingInterface: &handlerBinding{
@@ -177,7 +189,7 @@ func (b *Container) newHandlerBinding(handler http.Handler, in, mn string) (ret
 			i:       handler,
 		}}
 	// No Injections needed here
-	b.bindingContainer[in][mn] = ret
+	b.addBinding(ret)
 	return
 }
 
@@ -189,7 +201,7 @@ func (b *Container) newHandlerFuncBinding(handler http.HandlerFunc, in, mn strin
 			i:       nil,
 		}}
 	// No Injections needed here
-	b.bindingContainer[in][mn] = ret
+	b.addBinding(ret)
 	return
 }
 
@@ -202,7 +214,7 @@ func (b *Container) newMethodBinding(i interface{}, x int, in, mn string) (ret B
 		i:       i,
 	}}
 	ret.addGlobalInjections()
-	b.bindingContainer[in][mn] = ret
+	b.addBinding(ret)
 	return
 }
 
@@ -213,7 +225,7 @@ func (b *Container) newFunctionBinding(i interface{}, in, mn string) (ret Bindin
 		i:       i,
 	}}
 	ret.addGlobalInjections()
-	b.bindingContainer[in][mn] = ret
+	b.addBinding(ret)
 	return
 }
 
@@ -226,7 +238,7 @@ func (b *Container) newAttributeBinding(i interface{}, x int, in, mn string) (re
 		i:       i,
 	}}
 	ret.addGlobalInjections()
-	b.bindingContainer[in][mn] = ret
+	b.addBinding(ret)
 	return
 }
 
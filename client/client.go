@@ -2,10 +2,10 @@ package client
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
@@ -13,8 +13,11 @@ import (
 )
 
 const (
-	ALPHA      = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
-	CRIDLength = 20
+	ALPHA               = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	CRIDLength          = 20
+	JobInterface        = "gotojs"
+	DefaultPollInterval = 500 * time.Millisecond
+	DefaultAwaitTimeout = 10 * time.Minute
 )
 
 type Client struct {
@@ -24,6 +27,9 @@ type Client struct {
 	baseCRID    string
 	callCount   int
 	Header      http.Header
+	Retries     int                       //Amount of retries after a failed remote call. Retries reuse the CRID.
+	Signer      func(*http.Request) error //Optional signer that is applied to each request.
+	Timeout     time.Duration             //Maximum time to await an asynchronous job. If 0, DefaultAwaitTimeout is used.
 }
 
 //BinaryResponse represents a non json content which cannot be inspected by gotojs
@@ -62,10 +68,11 @@ func NewBinaryResponse(res *http.Response) (ret *BinaryResponse) {
 //generateCRID generates a random corelation ID.
 func generateCRID() (ret string) {
 	rb := make([]byte, CRIDLength)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	alpha := []byte(ALPHA)
-	for i := 0; i < CRIDLength; i++ {
-		rb[i] = alpha[r.Intn(len(alpha))]
+	if _, err := rand.Read(rb); err != nil {
+		panic(fmt.Errorf("Could not generate CRID: %s", err))
+	}
+	for i, b := range rb {
+		rb[i] = ALPHA[int(b)%len(ALPHA)]
 	}
 	return string(rb)
 }
@@ -80,7 +87,8 @@ func (c *Client) nextCRID() (ret string) {
 
 //NewProxyClient creates a new gotojs client that can be used to
 // proxy incoming requests to a remote gotojs instance from within a local
-// gotojs instance.
+// gotojs instance. The CRIDs of the proxied calls are derived from the CRID of the
+// incoming request but unique for each proxy client.
 func NewProxyClient(c *http.Client, jar http.CookieJar, bu *url.URL, ph string, crid string) (ret *Client) {
 	if c == nil {
 		c = &http.Client{}
@@ -89,7 +97,7 @@ func NewProxyClient(c *http.Client, jar http.CookieJar, bu *url.URL, ph string,
 	ret = &Client{
 		Client:      c,
 		baseUrl:     bu,
-		baseCRID:    crid,
+		baseCRID:    crid + "-" + generateCRID(),
 		Header:      make(http.Header, 0),
 		proxyHeader: ph,
 	}
@@ -143,22 +151,36 @@ func (c *Client) Invoke(in, mn string, args ...interface{}) (ret interface{}, er
 		return nil, fmt.Errorf("Cannot encode remote request body: %s", err)
 	}
 
-	buf := bytes.NewBuffer(by)
-	req, err := http.NewRequest("POST", c.url(in, mn), buf)
-	if err != nil {
-		return nil, fmt.Errorf("Cannot create remote request: %s", err)
-	}
-
 	//Build request Headers
-	req.Header = c.Header
-	req.Header.Set("Content-Type", "application/json")
+	header := c.Header
+	header.Set("Content-Type", "application/json")
 	if len(c.proxyHeader) > 0 {
-		req.Header.Set("x-gotojs-proxy", c.proxyHeader)
+		header.Set("x-gotojs-proxy", c.proxyHeader)
+	}
+	header.Set("x-gotojs-crid", c.nextCRID())
+
+	//Perform remote call. Retries use the same CRID so that the remote side is able to
+	//detect them.
+	var resp *http.Response
+	for try := 0; try <= c.Retries; try++ {
+		var req *http.Request
+		req, err = http.NewRequest("POST", c.url(in, mn), bytes.NewBuffer(by))
+		if err != nil {
+			return nil, fmt.Errorf("Cannot create remote request: %s", err)
+		}
+		req.Header = header
+
+		if c.Signer != nil {
+			if err = c.Signer(req); err != nil {
+				return nil, fmt.Errorf("Cannot sign remote request: %s", err)
+			}
+		}
+
+		if resp, err = c.Client.Do(req); err == nil {
+			break
+		}
 	}
-	req.Header.Set("x-gotojs-crid", c.nextCRID())
 
-	//Perform remote call
-	resp, err := c.Client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("Remote request call failed: %s", err)
 	}
@@ -185,3 +207,59 @@ func (c *Client) Invoke(in, mn string, args ...interface{}) (ret interface{}, er
 	}
 	return
 }
+
+//jobID extracts the job id of an asynchronous call response.
+func jobID(o interface{}) (string, error) {
+	if m, ok := o.(map[string]interface{}); ok {
+		if id, ok := m["ID"].(string); ok {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("Response is not a job status: %v", o)
+}
+
+//Await polls the status of an asynchronous job on the remote site until it is finished
+// and returns its result. If interval is 0, DefaultPollInterval is used. An error is returned
+// if the job is not finished within the timeout of the client.
+func (c *Client) Await(id string, interval time.Duration) (ret interface{}, err error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultAwaitTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	for {
+		var s interface{}
+		if s, err = c.Invoke(JobInterface, "JobStatus", id); err != nil {
+			return
+		}
+		st, _ := s.(map[string]interface{})
+		switch st["State"] {
+		case "done":
+			return c.Invoke(JobInterface, "JobResult", id)
+		case "failed", "canceled":
+			return nil, fmt.Errorf("Job %s %s: %v", id, st["State"], st["Error"])
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("Job %s is not finished after %s.", id, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
+
+//InvokeAndAwait invokes an asynchronous method/binding on the remote site and waits
+// until the job is finished.
+func (c *Client) InvokeAndAwait(in, mn string, args ...interface{}) (ret interface{}, err error) {
+	if ret, err = c.Invoke(in, mn, args...); err != nil {
+		return
+	}
+
+	id, err := jobID(ret)
+	if err != nil {
+		return nil, err
+	}
+	return c.Await(id, DefaultPollInterval)
+}
@@ -1,7 +1,10 @@
 package client
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGenerateCRID(t *testing.T) {
@@ -17,3 +20,17 @@ func TestGenerateCRID(t *testing.T) {
 		t.Errorf("2nd generated id is euqal the first one: %s/%s", id, id2)
 	}
 }
+
+func TestAwaitTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ID":"1","State":"running"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL + "/gotojs")
+	c.Timeout = 50 * time.Millisecond
+	if _, err := c.Await("1", 10*time.Millisecond); err == nil {
+		t.Errorf("Unfinished job has been awaited forever.")
+	}
+}
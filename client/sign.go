@@ -0,0 +1,85 @@
+package client
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	HMACScheme          = "GOTOJS-HMAC"
+	HeaderDate          = "x-gotojs-date"
+	HeaderNonce         = "x-gotojs-nonce"
+	HeaderAuthorization = "Authorization"
+	NonceLength         = 16 // in bytes
+)
+
+// StringToSign compiles the canonical representation of a request that is signed by
+// the HMAC authentication scheme. The body of the request is read and restored.
+func StringToSign(req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return "", fmt.Errorf("Cannot read request body: %s", err)
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	}
+	sum := sha256.Sum256(body)
+	return strings.Join([]string{
+		req.Method,
+		req.URL.RequestURI(),
+		req.Header.Get(HeaderDate),
+		req.Header.Get(HeaderNonce),
+		hex.EncodeToString(sum[:])}, "\n"), nil
+}
+
+// Signature calculates the HMAC signature of a request using the given secret.
+func Signature(req *http.Request, secret []byte) (string, error) {
+	sts, err := StringToSign(req)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(sts))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// generateNonce generates a random nonce which makes each signed request unique.
+func generateNonce() string {
+	b := make([]byte, NonceLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("Could not generate nonce: %s", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// SignRequest signs the request by the HMAC authentication scheme. The current time and a
+// random nonce are set as headers and the signature is placed in the authorization header.
+func SignRequest(req *http.Request, keyID string, secret []byte) error {
+	req.Header.Set(HeaderDate, time.Now().UTC().Format(time.RFC1123))
+	req.Header.Set(HeaderNonce, generateNonce())
+	sig, err := Signature(req, secret)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HeaderAuthorization, fmt.Sprintf("%s %s:%s", HMACScheme, keyID, sig))
+	return nil
+}
+
+// HMACSigner returns a signer function for the Client that signs each request with the
+// given key.
+func HMACSigner(keyID string, secret []byte) func(*http.Request) error {
+	return func(req *http.Request) error {
+		return SignRequest(req, keyID, secret)
+	}
+}
@@ -0,0 +1,153 @@
+package gotojs
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// CoalesceStats contains the counters of a coalescing binding.
+type CoalesceStats struct {
+	// Calls is the total amount of invocations.
+	Calls uint64
+
+	// Coalesced is the amount of invocations that shared the result of a concurrent identical one.
+	Coalesced uint64
+}
+
+// flight represents an invocation that is currently in progress and whose result is shared
+// by all identical concurrent invocations.
+type flight struct {
+	done   sync.WaitGroup
+	ret    interface{}
+	fault  interface{}
+	status int
+	header string
+}
+
+// fail passes the fault of the invocation to the caller of the given HTTP context, including
+// the error status the invocation has set.
+func (fl *flight) fail(hc *HTTPContext) {
+	if hc != nil && hc.Request != nil && fl.status > 0 {
+		hc.ErrorStatus = fl.status
+		hc.Response.Header().Set(DefaultHeaderError, fl.header)
+	}
+	panic(fl.fault)
+}
+
+// coalescer merges concurrent identical invocations of a binding into a single execution.
+type coalescer struct {
+	vary      []KeyFunc
+	flights   map[string]*flight
+	calls     uint64
+	coalesced uint64
+	mutex     sync.Mutex
+}
+
+// newCoalescer creates a coalescer whose invocation key is extended by the given key functions.
+func newCoalescer(vary []KeyFunc) *coalescer {
+	return &coalescer{
+		vary:    vary,
+		flights: make(map[string]*flight)}
+}
+
+// do executes the function f unless an identical invocation is already in progress. In this
+// case the result of the running invocation is returned. Panics are passed to all callers along
+// with the error status set on the HTTP context of the executing caller.
+func (c *coalescer) do(key string, hc *HTTPContext, f func() interface{}) interface{} {
+	atomic.AddUint64(&c.calls, 1)
+
+	c.mutex.Lock()
+	if fl, found := c.flights[key]; found {
+		c.mutex.Unlock()
+		atomic.AddUint64(&c.coalesced, 1)
+		fl.done.Wait()
+		if fl.fault != nil {
+			fl.fail(hc)
+		}
+		return fl.ret
+	}
+	fl := &flight{}
+	fl.done.Add(1)
+	c.flights[key] = fl
+	c.mutex.Unlock()
+
+	defer func() {
+		if re := recover(); re != nil {
+			fl.fault = re
+			if hc != nil && hc.Request != nil {
+				fl.status, fl.header = hc.ErrorStatus, hc.Response.Header().Get(DefaultHeaderError)
+			}
+		}
+		c.mutex.Lock()
+		delete(c.flights, key)
+		c.mutex.Unlock()
+		fl.done.Done()
+		if fl.fault != nil {
+			panic(fl.fault)
+		}
+	}()
+
+	fl.ret = f()
+	return fl.ret
+}
+
+// stats returns a snapshot of the counters.
+func (c *coalescer) stats() CoalesceStats {
+	return CoalesceStats{
+		Calls:     atomic.LoadUint64(&c.calls),
+		Coalesced: atomic.LoadUint64(&c.coalesced)}
+}
+
+// Coalesce enables request coalescing for the binding. Concurrent invocations with identical
+// arguments share a single execution and its result. The key is compiled from interface name,
+// method name and the arguments and can be extended by key functions which derive further parts
+// from the injections. Calls of bindings receiving the identity of the caller are keyed by
+// CallerKey as well. This is only supposed to be used for idempotent bindings that do not return
+// a Binary.
+func (b Binding) Coalesce(vary ...KeyFunc) Binding {
+	b.base().coalescer = newCoalescer(vary)
+	return b
+}
+
+// Coalesce enables request coalescing for all given bindings. See Binding.Coalesce for more information.
+func (bs Bindings) Coalesce(vary ...KeyFunc) Bindings {
+	for _, b := range bs {
+		b.Coalesce(vary...)
+	}
+	return bs
+}
+
+// CoalesceStats returns the coalescing counters of the binding. If coalescing is not enabled,
+// empty stats are returned.
+func (b Binding) CoalesceStats() CoalesceStats {
+	if c := b.base().coalescer; c != nil {
+		return c.stats()
+	}
+	return CoalesceStats{}
+}
+
+// CoalesceStats returns the coalescing counters of all bindings that have coalescing enabled.
+// The map is keyed by the binding name.
+func (b *Container) CoalesceStats() map[string]CoalesceStats {
+	ret := make(map[string]CoalesceStats)
+	for _, bi := range b.Bindings() {
+		if c := bi.base().coalescer; c != nil {
+			ret[bi.Name()] = c.stats()
+		}
+	}
+	return ret
+}
+
+// call is an internally used method that performs the actual invocation of the binding after
+// the filter chain has been passed. Coalescing is applied here if enabled.
+func (b Binding) call(inj Injections, args []interface{}) interface{} {
+	c := b.base().coalescer
+	if c == nil {
+		return b.invokeI(inj, args)
+	}
+
+	hc, _ := inj[typeOfHTTPContext].(*HTTPContext)
+	return c.do(callKey(b, inj, args, c.vary), hc, func() interface{} {
+		return b.invokeI(inj, args)
+	})
+}
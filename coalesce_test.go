@@ -0,0 +1,143 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCoalesce(t *testing.T) {
+	co := NewContainer()
+	var executions int32
+	started := make(chan bool, 5)
+	release := make(chan bool)
+	b := co.ExposeFunction(func(i int) int {
+		atomic.AddInt32(&executions, 1)
+		started <- true
+		<-release
+		return i * 2
+	}, "Coalesce", "Double").Coalesce()[0]
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 5)
+	for i := 0; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = b.Invoke(21)
+		}(i)
+	}
+
+	// Release the execution once all other calls have joined it.
+	<-started
+	for b.CoalesceStats().Coalesced < uint64(len(results)-1) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if executions != 1 {
+		t.Errorf("Concurrent identical calls were not coalesced: %d/%d", executions, 1)
+	}
+
+	for _, r := range results {
+		if r != 42 {
+			t.Errorf("Unexpected result of coalesced call: %v/%d", r, 42)
+		}
+	}
+
+	if s := co.CoalesceStats()[b.Name()]; s.Calls != 5 || s.Coalesced != 4 {
+		t.Errorf("Invalid coalescing stats: %d/%d calls, %d/%d coalesced", s.Calls, 5, s.Coalesced, 4)
+	}
+
+	b.Invoke(1)
+	b.Invoke(1)
+	if executions != 3 {
+		t.Errorf("Sequential calls must not be coalesced: %d/%d", executions, 3)
+	}
+}
+
+func TestCoalescePanic(t *testing.T) {
+	co := NewContainer()
+	b := co.ExposeFunction(func() int { panic("Failed on purpose.") }, "Coalesce", "Fail").Coalesce()[0]
+
+	defer func() {
+		if re := recover(); re == nil {
+			t.Errorf("Panic of coalesced call has not been passed.")
+		}
+	}()
+	b.Invoke()
+}
+
+func TestCoalesceCaller(t *testing.T) {
+	co := NewContainer()
+	started := make(chan bool, 2)
+	release := make(chan bool)
+	b := co.ExposeFunction(func(s *Session) string {
+		started <- true
+		<-release
+		return s.Get("user")
+	}, "Coalesce", "User").Coalesce()[0]
+
+	results := make(chan interface{}, 2)
+	for _, u := range []string{"alice", "bob"} {
+		s := NewSession()
+		s.Set("user", u)
+		s.sent = true
+		go func() { results <- b.call(b.mergeInjections(NewI(s)), nil) }()
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatalf("Calls of different callers have been coalesced.")
+		}
+	}
+	close(release)
+	if r1, r2 := <-results, <-results; r1 == r2 {
+		t.Errorf("Callers received the same result: %v/%v", r1, r2)
+	}
+}
+
+func TestCoalesceErrorStatus(t *testing.T) {
+	co := NewContainer()
+	started := make(chan bool, 2)
+	release := make(chan bool)
+	b := co.ExposeFunction(func(hc *HTTPContext) string {
+		started <- true
+		<-release
+		hc.Errorf(http.StatusConflict, "Conflict on purpose.")
+		return ""
+	}, "Coalesce", "Conflict").Coalesce()[0]
+
+	var wg sync.WaitGroup
+	hcs := make([]*HTTPContext, 2)
+	for i := range hcs {
+		hcs[i] = NewHTTPContext(httptest.NewRequest("GET", "/gotojs/Coalesce/Conflict", nil), httptest.NewRecorder())
+		wg.Add(1)
+		go func(hc *HTTPContext) {
+			defer wg.Done()
+			defer func() { recover() }()
+			b.call(b.mergeInjections(NewI(hc)), nil)
+		}(hcs[i])
+		if i == 0 {
+			<-started
+		}
+	}
+
+	for b.CoalesceStats().Coalesced < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	for i, hc := range hcs {
+		if hc.ErrorStatus != http.StatusConflict || hc.Response.Header().Get(DefaultHeaderError) != "Conflict on purpose." {
+			t.Errorf("#%d: Error status has not been passed: %d/%d", i, hc.ErrorStatus, http.StatusConflict)
+		}
+	}
+}
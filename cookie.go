@@ -0,0 +1,205 @@
+package gotojs
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits of the session cookie.
+const (
+	DefaultCookieChunkSize = 3800
+	DefaultCookieMaxChunks = 8
+)
+
+// CookiePolicy declares the attributes of the session cookie.
+type CookiePolicy struct {
+	Name     string
+	Path     string
+	Domain   string
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+
+	// MaxAge is the lifetime of the cookie. If 0, the cookie lives as long as the session.
+	// If negative, the cookie is removed when the browser is closed.
+	MaxAge time.Duration
+
+	// Sliding renews the expiry of the session on activity. The session is renewed once half
+	// of its time to live has passed.
+	Sliding bool
+
+	// ChunkSize is the maximum length of a cookie value. Larger sessions are split across
+	// numbered cookies like "gotojs.1", "gotojs.2" etc.
+	ChunkSize int
+
+	// MaxChunks is the maximum number of cookies a session may be split into. Larger sessions
+	// are rejected with an error.
+	MaxChunks int
+}
+
+// NewCookiePolicy creates a policy with the default cookie name and path. The cookie is not
+// accessible by scripts and not sent along with cross site requests.
+func NewCookiePolicy() *CookiePolicy {
+	return &CookiePolicy{
+		Name:      DefaultCookieName,
+		Path:      DefaultCookiePath,
+		HttpOnly:  true,
+		SameSite:  http.SameSiteLaxMode,
+		ChunkSize: DefaultCookieChunkSize,
+		MaxChunks: DefaultCookieMaxChunks}
+}
+
+// ParseSameSite converts the SameSite attribute "lax", "strict" or "none" to its http
+// representation.
+func ParseSameSite(v string) (http.SameSite, error) {
+	switch strings.ToLower(v) {
+	case "":
+		return http.SameSiteDefaultMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return http.SameSiteDefaultMode, fmt.Errorf("Invalid SameSite attribute \"%s\".", v)
+}
+
+// apply sets the attributes of the policy to the cookie of the given session.
+func (p *CookiePolicy) apply(c *http.Cookie, s *Session) {
+	c.Name = p.Name
+	c.Path = p.Path
+	c.Domain = p.Domain
+	c.Secure = p.Secure
+	c.HttpOnly = p.HttpOnly
+	c.SameSite = p.SameSite
+
+	switch {
+	case p.MaxAge > 0:
+		c.MaxAge = int(p.MaxAge / time.Second)
+	case p.MaxAge == 0 && s.ttl > 0:
+		if left := int(time.Until(s.expires()) / time.Second); left > 0 {
+			c.MaxAge = left
+		} else {
+			c.MaxAge = -1
+		}
+	}
+}
+
+// chunkName returns the name of the i-th cookie chunk. The first chunk carries the plain name.
+func (p *CookiePolicy) chunkName(i int) string {
+	if i == 0 {
+		return p.Name
+	}
+	return p.Name + "." + strconv.Itoa(i)
+}
+
+// limits returns the chunk size and the maximum number of chunks.
+func (p *CookiePolicy) limits() (size, max int) {
+	size, max = p.ChunkSize, p.MaxChunks
+	if size <= 0 {
+		size = DefaultCookieChunkSize
+	}
+	if max <= 0 {
+		max = DefaultCookieMaxChunks
+	}
+	return
+}
+
+// split splits the cookie into chunks if its value exceeds the chunk size. The value of the
+// first chunk is prefixed by the number of chunks like "3:". It panics if the value requires
+// more chunks than allowed.
+func (p *CookiePolicy) split(c *http.Cookie) []*http.Cookie {
+	size, max := p.limits()
+	if len(c.Value) <= size {
+		return []*http.Cookie{c}
+	}
+
+	n := (len(c.Value) + size - 1) / size
+	if n > max {
+		panic(fmt.Errorf("Session of %d bytes exceeds the cookie limit of %d chunks with %d bytes each. Consider using a session store.", len(c.Value), max, size))
+	}
+
+	ret := make([]*http.Cookie, n)
+	for i := range ret {
+		end := (i + 1) * size
+		if end > len(c.Value) {
+			end = len(c.Value)
+		}
+		cc := *c
+		cc.Name = p.chunkName(i)
+		cc.Value = c.Value[i*size : end]
+		if i == 0 {
+			cc.Value = strconv.Itoa(n) + ":" + cc.Value
+		}
+		ret[i] = &cc
+	}
+	return ret
+}
+
+// join reads the session cookie from the request and reassembles it if it has been split. It
+// also returns the number of chunk cookies found in the request in order to clean up stale
+// chunks. The error is http.ErrNoCookie if there is no session cookie.
+func (p *CookiePolicy) join(r *http.Request) (c *http.Cookie, chunks int, err error) {
+	for chunks = 1; ; chunks++ {
+		if _, e := r.Cookie(p.chunkName(chunks)); e != nil {
+			break
+		}
+	}
+
+	if c, err = r.Cookie(p.Name); err != nil {
+		return
+	}
+
+	i := strings.Index(c.Value, ":")
+	if i < 0 {
+		return
+	}
+
+	n, err := strconv.Atoi(c.Value[:i])
+	if _, max := p.limits(); err != nil || n < 1 || n > max {
+		return nil, chunks, fmt.Errorf("Invalid number of session cookie chunks: \"%s\".", c.Value[:i])
+	}
+
+	value := c.Value[i+1:]
+	for j := 1; j < n; j++ {
+		cc, e := r.Cookie(p.chunkName(j))
+		if e != nil {
+			return nil, chunks, fmt.Errorf("Session cookie chunk %d of %d is missing.", j, n)
+		}
+		value += cc.Value
+	}
+	return &http.Cookie{Name: p.Name, Value: value}, chunks, nil
+}
+
+// expire returns a cookie that removes the i-th cookie chunk on client side.
+func (p *CookiePolicy) expire(i int) *http.Cookie {
+	return &http.Cookie{Name: p.chunkName(i), Path: p.Path, Domain: p.Domain, MaxAge: -1}
+}
+
+// slide renews the given session if half of its time to live has passed.
+func (p *CookiePolicy) slide(s *Session) {
+	if !p.Sliding || s.ttl <= 0 || s.issued.IsZero() {
+		return
+	}
+	if time.Since(s.issued) > s.ttl/2 {
+		s.issued = time.Now()
+		s.dirty = true
+	}
+}
+
+// SetCookiePolicy sets the attributes of the session cookie.
+func (f *Container) SetCookiePolicy(p *CookiePolicy) {
+	if p == nil || len(p.Name) == 0 {
+		panic(fmt.Errorf("Cookie policy requires a cookie name."))
+	}
+	f.cookie = p
+}
+
+// CookiePolicy returns the attributes of the session cookie.
+func (f *Container) CookiePolicy() *CookiePolicy {
+	return f.cookie
+}
@@ -0,0 +1,142 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookiePolicy(t *testing.T) {
+	co := NewContainer(Properties{
+		P_FLAGS:          Flag2Param(F_CLEAR),
+		P_COOKIENAME:     "sid",
+		P_COOKIEPATH:     "/app",
+		P_COOKIEDOMAIN:   "example.com",
+		P_COOKIESECURE:   "true",
+		P_COOKIESAMESITE: "strict",
+		P_SESSIONTTL:     "1h"})
+	co.ExposeFunction(func(s *Session) string {
+		if v := s.Get("name"); len(v) > 0 {
+			return v
+		}
+		s.Set("name", "gotojs")
+		return "new"
+	}, "Cookie", "Name")
+	h := co.Setup()
+
+	call := func(c *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/gotojs/Cookie/Name", strings.NewReader("[]"))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		if c != nil {
+			req.AddCookie(c)
+		}
+		return record(h, req)
+	}
+
+	cookies := call(nil).Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("No session cookie set.")
+	}
+	c := cookies[0]
+	if c.Name != "sid" || c.Path != "/app" || c.Domain != "example.com" || !c.Secure || !c.HttpOnly || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("Cookie policy has not been applied: %s", c)
+	}
+	if c.MaxAge <= 3500 || c.MaxAge > 3600 {
+		t.Errorf("Cookie max age does not follow the session ttl: %d", c.MaxAge)
+	}
+
+	if r := call(c); r.Body.String() != `"gotojs"` {
+		t.Errorf("Session has not been read from the configured cookie: %s", r.Body.String())
+	}
+
+	if r := call(&http.Cookie{Name: DefaultCookieName, Value: c.Value}); r.Body.String() != `"new"` {
+		t.Errorf("Session has been read from the default cookie: %s", r.Body.String())
+	}
+}
+
+func TestInvalidSessionTTL(t *testing.T) {
+	for _, v := range []string{"1 hour", "-1h"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Invalid session ttl has been accepted: %s", v)
+				}
+			}()
+			NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR), P_SESSIONTTL: v})
+		}()
+	}
+}
+
+func TestSlidingSession(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR), P_SLIDINGSESSION: "true"})
+	co.SessionTTL(time.Hour)
+	co.ExposeFunction(func(s *Session) string { return s.Get("k") }, "Cookie", "Get")
+	h := co.Setup()
+
+	s := NewSession()
+	s.issued, s.ttl = time.Now().Add(-40*time.Minute), time.Hour
+	s.Set("k", "v")
+
+	req := httptest.NewRequest("POST", "/gotojs/Cookie/Get", strings.NewReader("[]"))
+	req.Header.Set(CTHeader, DefaultMimeType)
+	req.AddCookie(s.Cookie(DefaultCookieName, DefaultCookiePath, co.key))
+	r := record(h, req)
+	if r.Body.String() != `"v"` {
+		t.Fatalf("Session could not be read: %s", r.Body.String())
+	}
+
+	cookies := r.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Session has not been renewed.")
+	}
+	if rs := SessionFromCookie(cookies[0], co.keys...); time.Since(rs.issued) > time.Minute || rs.Get("k") != "v" {
+		t.Errorf("Renewed session is invalid: %s %v", rs.issued, rs.Properties)
+	}
+}
+
+func TestChunkedCookies(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR), P_COOKIECHUNKS: "3"})
+	co.CookiePolicy().ChunkSize = 100
+	co.ExposeFunction(func(s *Session, v string) int {
+		s.Set("v", v)
+		return len(s.Get("v"))
+	}, "Cookie", "Set")
+	co.ExposeFunction(func(s *Session) string { return s.Get("v") }, "Cookie", "Get")
+	h := co.Setup()
+
+	call := func(path, body string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return record(h, req)
+	}
+
+	// Random content does not compress.
+	large := Encoding.EncodeToString(GenerateKey(120))
+	cookies := call("/gotojs/Cookie/Set", `["`+large+`"]`, nil).Result().Cookies()
+	if len(cookies) < 2 || cookies[1].Name != DefaultCookieName+".1" {
+		t.Fatalf("Large session has not been split: %v", cookies)
+	}
+
+	if r := call("/gotojs/Cookie/Get", "[]", cookies); r.Body.String() != `"`+large+`"` {
+		t.Errorf("Chunked session has not been reassembled: %s", r.Body.String())
+	}
+
+	if r := call("/gotojs/Cookie/Get", "[]", cookies[:1]); r.Body.String() != `""` {
+		t.Errorf("Incomplete session has been accepted: %s", r.Body.String())
+	}
+
+	shrunk := call("/gotojs/Cookie/Set", `["small"]`, cookies).Result().Cookies()
+	if len(shrunk) != len(cookies) || shrunk[len(shrunk)-1].MaxAge >= 0 {
+		t.Errorf("Stale chunks have not been removed: %v", shrunk)
+	}
+
+	huge := Encoding.EncodeToString(GenerateKey(400))
+	if r := call("/gotojs/Cookie/Set", `["`+huge+`"]`, nil); r.Code != http.StatusInternalServerError || len(r.Result().Cookies()) != 0 {
+		t.Errorf("Session exceeding the cookie limit has not been rejected: %d", r.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package gotojs
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy declares which cross origin requests are accepted by the container.
+type CORSPolicy struct {
+	// Origins contains the accepted origins. An origin is either given exactly like
+	// "https://app.example.com" or as pattern like "https://*.example.com". "*" accepts any origin.
+	Origins []string
+
+	// Methods contains the accepted methods of preflight requests.
+	Methods []string
+
+	// Headers contains the accepted request headers of preflight requests.
+	Headers []string
+
+	// ExposeHeaders contains the response headers the client may read.
+	ExposeHeaders []string
+
+	// Credentials allows cookies and authorization headers to be sent along with the requests.
+	// The engine is generated accordingly. It cannot be combined with the "*" origin.
+	Credentials bool
+
+	// MaxAge is the time the result of a preflight request may be cached by the client.
+	MaxAge time.Duration
+}
+
+// DefaultCORSPolicy accepts requests of any origin without credentials. Containers use a copy
+// of it.
+var DefaultCORSPolicy = &CORSPolicy{
+	Origins:       []string{"*"},
+	Methods:       []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+	Headers:       []string{CTHeader, DefaultHeaderCRID, DefaultHeaderCSRF, DefaultHeaderAPIKey, "Authorization"},
+	ExposeHeaders: []string{DefaultHeaderCRID, DefaultHeaderError}}
+
+// copy returns a deep copy of the policy.
+func (p *CORSPolicy) copy() *CORSPolicy {
+	c := *p
+	c.Origins = append([]string(nil), p.Origins...)
+	c.Methods = append([]string(nil), p.Methods...)
+	c.Headers = append([]string(nil), p.Headers...)
+	c.ExposeHeaders = append([]string(nil), p.ExposeHeaders...)
+	return &c
+}
+
+// allowOrigin returns the value of the Access-Control-Allow-Origin header for the given origin.
+// An empty string is returned if the origin is not accepted. The origin is never reflected for
+// "*", so credentialed requests of arbitrary origins are not accepted.
+func (p *CORSPolicy) allowOrigin(origin string) string {
+	for _, o := range p.Origins {
+		if o == "*" {
+			if p.Credentials {
+				continue
+			}
+			return "*"
+		}
+		if m, _ := path.Match(o, origin); m && len(origin) > 0 {
+			return origin
+		}
+	}
+	return ""
+}
+
+// apply sets the CORS headers of a regular response.
+func (p *CORSPolicy) apply(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	ao := p.allowOrigin(origin)
+	h := w.Header()
+	if ao != "*" {
+		h.Add("Vary", "Origin")
+	}
+	if len(ao) == 0 {
+		return false
+	}
+
+	h.Set("Access-Control-Allow-Origin", ao)
+	if p.Credentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(p.ExposeHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(p.ExposeHeaders, ", "))
+	}
+	return true
+}
+
+// preflight answers a preflight request.
+func (p *CORSPolicy) preflight(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	method := r.Header.Get("Access-Control-Request-Method")
+	if !p.apply(w, r) || !containsFoldS(p.Methods, method) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	for _, rh := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if rh = strings.TrimSpace(rh); len(rh) > 0 && !containsFoldS(p.Headers, rh) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}
+
+	h.Set("Access-Control-Allow-Methods", strings.Join(p.Methods, ", "))
+	if len(p.Headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(p.Headers, ", "))
+	}
+	if p.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// containsFoldS checks case-insensitively whether the string s is contained in a.
+func containsFoldS(a []string, s string) bool {
+	for _, v := range a {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS sets the CORS policy of the container. By default a copy of DefaultCORSPolicy is used.
+// If nil, no CORS headers are sent at all. It panics if the policy allows credentials for any
+// origin.
+func (b *Container) CORS(p *CORSPolicy) {
+	if p != nil && p.Credentials && ContainsS(p.Origins, "*") {
+		panic(fmt.Errorf("CORS policy must not allow credentials for any origin (\"*\")."))
+	}
+	b.cors = p
+	b.revision++
+}
+
+// serveCORS answers preflight requests. It returns true if the request has been answered.
+func (b *Container) serveCORS(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != "OPTIONS" || len(r.Header.Get("Access-Control-Request-Method")) == 0 {
+		return false
+	}
+
+	if b.cors == nil {
+		w.WriteHeader(http.StatusForbidden)
+	} else {
+		b.cors.preflight(w, r)
+	}
+	return true
+}
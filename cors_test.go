@@ -0,0 +1,110 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func corsRequest(h http.Handler, method, origin string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/gotojs/Test/Hello", nil)
+	req.Header.Set("Origin", origin)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	return record(h, req)
+}
+
+func TestDefaultCORS(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func() string { return "Hello" }, "Test", "Hello")
+	h := co.Setup()
+
+	if r := corsRequest(h, "GET", "http://other.com"); r.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Errorf("Default policy does not allow any origin: '%s'", r.Header().Get("Access-Control-Allow-Origin"))
+	}
+
+	r := corsRequest(h, "OPTIONS", "http://other.com",
+		"Access-Control-Request-Method", "POST",
+		"Access-Control-Request-Headers", "content-type, x-gotojs-crid")
+	if r.Code != http.StatusNoContent || !strings.Contains(r.Header().Get("Access-Control-Allow-Headers"), DefaultHeaderCRID) {
+		t.Errorf("Preflight has not been answered: %d %v", r.Code, r.Header())
+	}
+
+	if r := corsRequest(h, "OPTIONS", "http://other.com", "Access-Control-Request-Method", "DELETE"); r.Code != http.StatusNoContent {
+		t.Errorf("Preflight of DELETE has not been accepted: %d", r.Code)
+	}
+
+	co.cors.Origins[0] = "https://app.example.com"
+	if DefaultCORSPolicy.Origins[0] != "*" {
+		t.Errorf("Default policy is shared by the container.")
+	}
+}
+
+func TestCORSWildcardCredentials(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Credentials for any origin have been accepted.")
+			}
+		}()
+		co.CORS(&CORSPolicy{Origins: []string{"*"}, Credentials: true})
+	}()
+
+	p := &CORSPolicy{Origins: []string{"*"}}
+	co.CORS(p)
+	p.Credentials = true // Modified afterwards.
+	if o := p.allowOrigin("https://evil.com"); len(o) > 0 {
+		t.Errorf("Origin has been reflected for credentialed requests: %s", o)
+	}
+}
+
+func TestCORSPolicy(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	called := false
+	co.ExposeFunction(func() string {
+		called = true
+		return "Hello"
+	}, "Test", "Hello")
+	co.CORS(&CORSPolicy{
+		Origins:     []string{"https://app.example.com", "https://*.example.org"},
+		Methods:     []string{"GET", "POST"},
+		Headers:     []string{CTHeader, DefaultHeaderCRID},
+		Credentials: true,
+		MaxAge:      10 * time.Minute})
+	h := co.Setup()
+
+	r := corsRequest(h, "GET", "https://app.example.com")
+	if r.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || r.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Errorf("Exact origin has not been accepted: %v", r.Header())
+	}
+
+	if r := corsRequest(h, "GET", "https://x.example.org"); r.Header().Get("Access-Control-Allow-Origin") != "https://x.example.org" {
+		t.Errorf("Origin pattern has not been accepted: %v", r.Header())
+	}
+
+	if r := corsRequest(h, "GET", "https://evil.com"); len(r.Header().Get("Access-Control-Allow-Origin")) > 0 {
+		t.Errorf("Unknown origin has been accepted: %v", r.Header())
+	}
+
+	called = false
+	r = corsRequest(h, "OPTIONS", "https://app.example.com", "Access-Control-Request-Method", "POST")
+	if r.Code != http.StatusNoContent || r.Header().Get("Access-Control-Max-Age") != "600" || called {
+		t.Errorf("Invalid preflight response: %d %v (binding called: %t)", r.Code, r.Header(), called)
+	}
+
+	if r := corsRequest(h, "OPTIONS", "https://app.example.com", "Access-Control-Request-Method", "DELETE"); r.Code != http.StatusForbidden {
+		t.Errorf("Preflight of a denied method has been accepted: %d", r.Code)
+	}
+
+	if r := corsRequest(h, "OPTIONS", "https://app.example.com", "Access-Control-Request-Method", "POST", "Access-Control-Request-Headers", "x-custom"); r.Code != http.StatusForbidden {
+		t.Errorf("Preflight of a denied header has been accepted: %d", r.Code)
+	}
+
+	if e := record(h, httptest.NewRequest("GET", "/gotojs/", nil)).Body.String(); !strings.Contains(e, "withCredentials") {
+		t.Errorf("Engine does not send credentials.")
+	}
+}
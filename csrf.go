@@ -0,0 +1,165 @@
+package gotojs
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// Default values of the CSRF protection.
+const (
+	DefaultHeaderCSRF  = "x-gotojs-csrf"
+	DefaultSessionCSRF = "gotojs.csrf"
+)
+
+// CSRFToken returns the CSRF token of the session. A new token is generated if the session
+// does not contain one yet.
+func (s *Session) CSRFToken() string {
+	if t := s.Get(DefaultSessionCSRF); len(t) > 0 {
+		return t
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Errorf("Could not generate CSRF token: %s", err))
+	}
+	t := base64.RawURLEncoding.EncodeToString(buf)
+	s.Set(DefaultSessionCSRF, t)
+	return t
+}
+
+// EnableCSRF enables the CSRF protection for all bindings that are not marked as safe. Such
+// bindings must be called via POST by a request carrying the CSRF token of the session in the
+// DefaultHeaderCSRF header. The token is delivered along with the engine if the engine is
+// requested by the site itself or one of the given trusted origins. If the Origin or Referer
+// header of a call is set, it must either match the host of the request or one of the trusted
+// origins. Calls without session cookie are not affected.
+func (b *Container) EnableCSRF(trustedOrigins ...string) {
+	b.csrf = true
+	b.csrfOrigins = trustedOrigins
+}
+
+// Safe marks the binding as free of side effects. Safe bindings may be called via GET and are
+// not subject to the CSRF protection.
+func (b Binding) Safe() Binding {
+	b.base().safe = true
+	return b
+}
+
+// Safe marks the given bindings as free of side effects.
+func (bs Bindings) Safe() Bindings {
+	for _, b := range bs {
+		b.Safe()
+	}
+	return bs
+}
+
+// RequireCSRF enables the CSRF protection for the binding regardless of the container setting.
+func (b Binding) RequireCSRF() Binding {
+	b.base().requireCSRF = true
+	return b
+}
+
+// RequireCSRF enables the CSRF protection for the given bindings.
+func (bs Bindings) RequireCSRF() Bindings {
+	for _, b := range bs {
+		b.RequireCSRF()
+	}
+	return bs
+}
+
+// csrfProtected returns true if the binding is subject to the CSRF protection.
+func (b Binding) csrfProtected() bool {
+	bb := b.base()
+	return !bb.safe && (bb.requireCSRF || bb.container.csrf)
+}
+
+// writeCSRF appends the CSRF token of the session to the engine code. Any site may include the
+// engine by a script tag, so the token is only written if the engine has been requested by the
+// site itself or a trusted origin.
+func (f *Container) writeCSRF(out io.Writer, r *http.Request, s *Session) {
+	if !f.csrf && !f.anyBinding(Binding.csrfProtected) {
+		return
+	}
+	if !f.trustedRequest(r) {
+		return
+	}
+	fmt.Fprintf(out, "\n%s.HTTP.CSRFToken = \"%s\";\n", f.namespace, s.CSRFToken())
+}
+
+// trustedRequest checks whether the request has been initiated by the site itself or a trusted
+// origin. This is taken from the Sec-Fetch-Site header sent by browsers. Otherwise the Origin or
+// Referer header must be trusted. Requests without any of these headers are not trusted.
+func (f *Container) trustedRequest(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		origin = r.Header.Get("Referer")
+	}
+	return len(origin) > 0 && f.trustedOrigin(r, origin)
+}
+
+// anyBinding returns true if the given predicate holds for any binding of the container.
+func (f *Container) anyBinding(p func(Binding) bool) bool {
+	for _, b := range f.Bindings() {
+		if p(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedOrigin checks whether the given origin matches the host of the request or one of the
+// trusted origins.
+func (f *Container) trustedOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if u.Host == r.Host {
+		return true
+	}
+	for _, o := range f.csrfOrigins {
+		if o == u.Scheme+"://"+u.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCSRF enforces the CSRF protection of the binding.
+func (b Binding) checkCSRF(hc *HTTPContext, s *Session) {
+	if !b.csrfProtected() {
+		return
+	}
+
+	r := hc.Request
+	f := b.base().container
+	if _, err := r.Cookie(f.cookie.Name); err != nil {
+		return // No session, nothing to forge.
+	}
+
+	if r.Method == "GET" {
+		hc.Response.Header().Set("Allow", "POST")
+		hc.Errorf(http.StatusMethodNotAllowed, "Binding \"%s\" is not safe and must be called via POST.", b.Name())
+	}
+
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		origin = r.Header.Get("Referer")
+	}
+	if len(origin) > 0 && !f.trustedOrigin(r, origin) {
+		hc.Errorf(http.StatusForbidden, "Origin \"%s\" is not trusted.", origin)
+	}
+
+	token := s.Get(DefaultSessionCSRF)
+	if len(token) == 0 || subtle.ConstantTimeCompare([]byte(token), []byte(r.Header.Get(DefaultHeaderCSRF))) != 1 {
+		hc.Errorf(http.StatusForbidden, "Invalid CSRF token for \"%s\".", b.Name())
+	}
+}
@@ -0,0 +1,81 @@
+package gotojs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestCSRF(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.EnableCSRF("https://trusted.example.com")
+	co.ExposeFunction(func() string { return "changed" }, "Account", "Change")
+	co.ExposeFunction(func() string { return "read" }, "Account", "Read").Safe()
+	h := co.Setup()
+
+	token := regexp.MustCompile(`HTTP.CSRFToken = "([^"]+)"`)
+	engine := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/gotojs/", nil)
+		if len(header) > 0 {
+			req.Header.Set(header, value)
+		}
+		return record(h, req)
+	}
+	for _, c := range [][]string{
+		{"", ""},
+		{"Sec-Fetch-Site", "cross-site"},
+		{"Referer", "https://evil.com/page"}} {
+		if r := engine(c[0], c[1]); token.MatchString(r.Body.String()) {
+			t.Errorf("Engine requested by an untrusted site contains the CSRF token: %s", c)
+		}
+	}
+	if r := engine("Referer", "https://trusted.example.com/app"); !token.MatchString(r.Body.String()) {
+		t.Errorf("Engine requested by a trusted origin does not contain the CSRF token.")
+	}
+
+	r := engine("Sec-Fetch-Site", "same-origin")
+	m := token.FindStringSubmatch(r.Body.String())
+	cookies := r.Result().Cookies()
+	if m == nil || len(cookies) == 0 {
+		t.Fatalf("Engine does not contain a CSRF token.")
+	}
+
+	call := func(method, url, token, origin string, cookie bool) int {
+		req := httptest.NewRequest(method, url, bytes.NewBufferString("[]"))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		if len(token) > 0 {
+			req.Header.Set(DefaultHeaderCSRF, token)
+		}
+		if len(origin) > 0 {
+			req.Header.Set("Origin", origin)
+		}
+		if cookie {
+			req.AddCookie(cookies[0])
+		}
+		return record(h, req).Code
+	}
+
+	cases := []struct {
+		method, url, token, origin string
+		cookie                     bool
+		status                     int
+	}{
+		{"POST", "/gotojs/Account/Change", m[1], "", true, http.StatusOK},
+		{"POST", "/gotojs/Account/Change", m[1], "https://trusted.example.com", true, http.StatusOK},
+		{"POST", "/gotojs/Account/Change", m[1], "http://example.com", true, http.StatusOK},
+		{"POST", "/gotojs/Account/Change", "", "", true, http.StatusForbidden},
+		{"POST", "/gotojs/Account/Change", "forged", "", true, http.StatusForbidden},
+		{"POST", "/gotojs/Account/Change", m[1], "https://evil.com", true, http.StatusForbidden},
+		{"GET", "/gotojs/Account/Change", m[1], "", true, http.StatusMethodNotAllowed},
+		{"GET", "/gotojs/Account/Read", "", "https://evil.com", true, http.StatusOK},
+		{"POST", "/gotojs/Account/Change", "", "", false, http.StatusOK},
+	}
+
+	for i, c := range cases {
+		if s := call(c.method, c.url, c.token, c.origin, c.cookie); s != c.status {
+			t.Errorf("#%d: Invalid status for %s %s: %d/%d", i, c.method, c.url, s, c.status)
+		}
+	}
+}
@@ -6,6 +6,77 @@ import (
 	"reflect"
 )
 
+// Rejection is raised by Reject in order to abort a call with a HTTP status.
+type Rejection struct {
+	Status  int
+	Message string
+}
+
+// Error implements the error interface.
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("%d: %s", r.Status, r.Message)
+}
+
+// Reject aborts the current call with the given HTTP status and message. It is meant to be
+// called by filters like:
+//
+//	return Reject(http.StatusForbidden, "Access to %s denied.", b.Name())
+//
+// Calls not made via HTTP panic with the *Rejection as error.
+func Reject(status int, format string, args ...interface{}) bool {
+	panic(&Rejection{Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// If adds a filter to all bindings of the container including those exposed later on.
+// Container filters are executed before the filters of the interfaces and those of the bindings.
+func (b *Container) If(f Filter) {
+	b.filters = append(b.filters, f)
+}
+
+// IfInterface adds a filter to all bindings of the named interface including those exposed
+// later on.
+func (b *Container) IfInterface(in string, f Filter) {
+	b.interfaceFilters[in] = append(b.interfaceFilters[in], f)
+}
+
+// ClearFilters removes the filters of the container and of all interfaces added by If and
+// IfInterface. Filters of the bindings are kept.
+func (b *Container) ClearFilters() {
+	b.filters = nil
+	b.interfaceFilters = make(map[string][]Filter)
+}
+
+// ClearInterfaceFilters removes the filters of the named interface added by IfInterface.
+func (b *Container) ClearInterfaceFilters(in string) {
+	delete(b.interfaceFilters, in)
+}
+
+// Exempt exempts the binding from the filters of the container and its interface, like a login
+// binding from a container wide authentication filter. Its own filters still apply.
+func (b Binding) Exempt() Binding {
+	b.base().exempt = true
+	return b
+}
+
+// Exempt exempts the given bindings from the filters of the container and their interfaces.
+func (bs Bindings) Exempt() Bindings {
+	for _, b := range bs {
+		b.Exempt()
+	}
+	return bs
+}
+
+// Filters returns the filter chain of the binding in the order it is executed. This includes
+// the filters of the container and the interface unless the binding is exempt.
+func (b Binding) Filters() (ret []Filter) {
+	bb := b.base()
+	if !bb.exempt {
+		ret = append(ret, bb.container.filters...)
+		ret = append(ret, bb.container.interfaceFilters[bb.interfaceName]...)
+	}
+	return append(ret, bb.filters...)
+}
+
 // AutoInjectF returns a filter function whose parameters will be automatically injected based
 // on their types. Besides explicitly announced Injections by SetupInjection, both the *Binding as
 // well as the full Injections container will be injected.
@@ -69,6 +69,18 @@ const (
 	P_APPLICATIONKEY = "appkey"
 	P_FLAGS          = "flags"
 	P_COOKIENAME     = "cookie"
+	P_JOBWORKERS     = "jobworkers"
+	P_JOBQUEUESIZE   = "jobqueue"
+	P_CACHESIZE      = "cachesize"
+	P_SESSIONTTL     = "sessionttl"
+	P_COOKIEPATH     = "cookiepath"
+	P_COOKIEDOMAIN   = "cookiedomain"
+	P_COOKIESECURE   = "cookiesecure"
+	P_COOKIEHTTPONLY = "cookiehttponly"
+	P_COOKIESAMESITE = "cookiesamesite"
+	P_COOKIEMAXAGE   = "cookiemaxage"
+	P_SLIDINGSESSION = "slidingsession"
+	P_COOKIECHUNKS   = "cookiechunks"
 )
 
 // Internally used constants and default values
@@ -90,6 +102,7 @@ const (
 	DefaultBasePath          = "."
 	DefaultCookieName        = "gotojs"
 	DefaultCookiePath        = "/"
+	DefaultSessionTTL        = 24 * time.Hour
 	DefaultPlatform          = "web"
 	DefaultMimeType          = "application/json"
 	DefaultHeaderCRID        = "x-gotojs-crid"
@@ -109,6 +122,10 @@ const (
 	tokenHeaderCRID        = "IH"
 	tokenContentType       = "CT"
 	tokenCRIDLength        = "CL"
+	tokenAsync             = "ASY"
+	tokenInternalInterface = "II"
+	tokenCredentials       = "CRED"
+	tokenHeaderCSRF        = "XH"
 )
 
 type cache struct {
@@ -191,7 +208,25 @@ type Properties map[string]string
 // it consists of a set of properties.
 type Session struct {
 	Properties
-	dirty bool
+	dirty  bool
+	issued time.Time
+	ttl    time.Duration
+	id     string
+	sent   bool // The session has been sent by the client.
+	store  SessionStore
+	policy *CookiePolicy
+	chunks int
+
+	payload    interface{}
+	payloadRaw string
+}
+
+// sessionEnvelope is the sealed content of a session cookie.
+type sessionEnvelope struct {
+	IssuedAt   int64      `json:"iat"`
+	Expires    int64      `json:"exp,omitempty"`
+	ID         string     `json:"id,omitempty"`
+	Properties Properties `json:"p,omitempty"`
 }
 
 //Flag2Param converts initialization flags to a string parameter.
@@ -206,8 +241,9 @@ func NewSession() *Session {
 		dirty:      false}
 }
 
-// SessionFromCookie reads a session object from the cookie.
-func SessionFromCookie(cookie *http.Cookie, key []byte) *Session {
+// SessionFromCookie reads a session object from the cookie. The cookie is decrypted by the
+// first matching key of the given keys. Expired sessions are rejected.
+func SessionFromCookie(cookie *http.Cookie, keys ...[]byte) *Session {
 	// Base64 decode
 	raw, err := Encoding.DecodeString(cookie.Value)
 	if err != nil {
@@ -215,7 +251,7 @@ func SessionFromCookie(cookie *http.Cookie, key []byte) *Session {
 	}
 
 	// Decrypt
-	ibuf := bytes.NewBuffer(Decrypt(raw, key))
+	ibuf := bytes.NewBuffer(Decrypt(raw, keys...))
 
 	// Enflate
 	fbuf := new(bytes.Buffer)
@@ -224,14 +260,47 @@ func SessionFromCookie(cookie *http.Cookie, key []byte) *Session {
 
 	// JSON Decoder
 	dec := json.NewDecoder(fbuf)
+	var env sessionEnvelope
+	if err = dec.Decode(&env); err != nil {
+		panic(errors.New(fmt.Sprintf("Could not decode (json) session: %s/%s", fbuf.String(), err.Error())))
+	}
+
+	if env.Expires > 0 && time.Now().Unix() >= env.Expires {
+		panic(fmt.Errorf("Session expired at %s.", time.Unix(env.Expires, 0)))
+	}
+
 	s := NewSession()
 	s.dirty = false
-	if err = dec.Decode(&s.Properties); err != nil {
-		panic(errors.New(fmt.Sprintf("Could not decode (json) session: %s/%s", fbuf.String(), err.Error())))
+	s.issued = time.Unix(env.IssuedAt, 0)
+	s.id = env.ID
+	s.sent = true
+	if env.Properties != nil {
+		s.Properties = env.Properties
 	}
 	return s
 }
 
+// ID returns the ID of the session. It is generated on first use and kept by the session cookie
+// from then on.
+func (s *Session) ID() string {
+	if len(s.id) == 0 {
+		s.id = newSessionID()
+		s.dirty = true
+	}
+	return s.id
+}
+
+// expires returns the time the session expires. It is zero if the session never expires.
+func (s *Session) expires() time.Time {
+	if s.issued.IsZero() {
+		s.issued = time.Now()
+	}
+	if s.ttl > 0 {
+		return s.issued.Add(s.ttl)
+	}
+	return time.Time{}
+}
+
 // Set sets a property value with the given key.
 func (s *Session) Set(key, val string) {
 	s.dirty = true
@@ -252,20 +321,55 @@ func (s *Session) Delete(key string) {
 
 // Flush updates the cookie on client side if it was changed.
 // In order to do so it sets the "Set-Cookie" header on the http
-// response
+// response. If the session is kept by a session store, the properties are saved to the store
+// and the cookie only holds the session ID. The cookie follows the cookie policy of the
+// container the session has been taken from.
 func (s *Session) Flush(w http.ResponseWriter, key []byte) {
+	s.syncPayload()
 	if s.dirty {
-		http.SetCookie(w, s.Cookie(DefaultCookieName, DefaultCookiePath, key))
+		if s.store != nil {
+			if err := s.store.Save(s.ID(), s.Properties, s.expires()); err != nil {
+				log.Printf("Could not save session: %s", err)
+				return
+			}
+		}
+		p := s.policy
+		if p == nil {
+			p = NewCookiePolicy()
+		}
+		c := s.Cookie(p.Name, p.Path, key)
+		p.apply(c, s)
+		chunks := p.split(c)
+		for _, cc := range chunks {
+			http.SetCookie(w, cc)
+		}
+
+		// Remove chunks of a previously larger session.
+		for i := len(chunks); i < s.chunks; i++ {
+			http.SetCookie(w, p.expire(i))
+		}
 	}
 }
 
 // Cookie generates a cookie object with the given name and path.
 // the cookie value is taken from the session properties, json encoded, defalted, encrypted with the given key and finally base64 encoded.
+// If the session is kept by a session store, only the session ID is taken.
 func (s *Session) Cookie(name, path string, key []byte) *http.Cookie {
 	c := new(http.Cookie)
 
+	env := sessionEnvelope{Properties: s.Properties}
+	if exp := s.expires(); !exp.IsZero() {
+		env.Expires = exp.Unix()
+	}
+	env.IssuedAt = s.issued.Unix()
+	env.ID = s.id
+	if s.store != nil {
+		env.ID = s.ID()
+		env.Properties = nil
+	}
+
 	//JSON Encoding:
-	b, err := json.Marshal(s.Properties)
+	b, err := json.Marshal(env)
 	if err != nil {
 		panic(fmt.Errorf("Cannot compile cookie: %s", err.Error()))
 	}
@@ -289,29 +393,11 @@ func (s *Session) Cookie(name, path string, key []byte) *http.Cookie {
 	return c
 }
 
-//SetCookies wraps the cookies into this session. This can effectively be used
-// as cookie proxy.
-func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
-	for _, c := range cookies {
-		//TODO: encapsulate more information
-		//log.Printf("Found Cookie: %s=%s",c.Name,c.Value)
-		s.Set(c.Name, c.Value)
-	}
-}
-
-//Cookies returns alls cookies that belong to this url. This can effectively be used
-// as cookie proxy.
-func (s *Session) Cookies(u *url.URL) []*http.Cookie {
-	ret := make([]*http.Cookie, 0)
-	for k, v := range s.Properties {
-		//TODO: encapsulate more information like expires etc.
-		//c := &http.Cookie{Name: k, Value: v, Path: url.Path, Domain: url.Host}
-		c := &http.Cookie{Name: k, Value: v, Path: "/", Domain: u.Host}
-		//log.Printf("Adding Cookie: %s",c)
-		ret = append(ret, c)
-	}
-	return ret
-}
+// Types of the request specific injections.
+var (
+	typeOfHTTPContext = reflect.TypeOf(&HTTPContext{})
+	typeOfSession     = reflect.TypeOf(&Session{})
+)
 
 // HTTPContext is a context object that will be injected by the container whenever an exposed method or function parameter
 // is of type *HTTPContext. It contains references to all relevant http related objects like request and
@@ -323,24 +409,74 @@ type HTTPContext struct {
 	ErrorStatus  int
 	ReturnStatus int
 	Container    *Container
+	Principal    *Principal
+}
+
+// SetKeys sets the keys used to seal the session cookies. The first key is used to seal new
+// cookies, all keys are accepted to open existing ones.
+func (f *Container) SetKeys(keys ...[]byte) {
+	if len(keys) == 0 {
+		panic(fmt.Errorf("At least one key is required."))
+	}
+	f.key = keys[0]
+	f.keys = keys
+}
+
+// RotateKey makes the given key the one used to seal new session cookies. Previous keys are
+// still accepted to open existing cookies until they are removed by SetKeys.
+func (f *Container) RotateKey(key []byte) {
+	f.SetKeys(append([][]byte{key}, f.keys...)...)
+}
+
+// SessionTTL sets the time after which sessions expire. If 0, sessions never expire.
+func (f *Container) SessionTTL(ttl time.Duration) {
+	f.sessionTTL = ttl
 }
 
 // Session tries to extract a session from the HTTPContext.
 // If it cannot be extracted, a new session is created.
-func (c *HTTPContext) Session(key []byte) (s *Session) {
+// If the container uses a session store, the properties are loaded from the store.
+func (c *HTTPContext) Session(keys ...[]byte) (s *Session) {
+	var store SessionStore
+	var chunks int
+	policy := NewCookiePolicy()
+	if c.Container != nil {
+		store, policy = c.Container.sessionStore, c.Container.cookie
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			// If something happens ... return fresh session
 			log.Printf("%s. Creating fresh session.", r)
 			s = NewSession()
 		}
+		if c.Container != nil {
+			s.ttl = c.Container.sessionTTL
+		}
+		s.store, s.policy, s.chunks = store, policy, chunks
+		policy.slide(s)
 	}()
-	cookie, err := c.Request.Cookie(DefaultCookieName)
-	if err != nil {
+	cookie, chunks, err := policy.join(c.Request)
+	if err == http.ErrNoCookie {
 		s = NewSession()
 		//panic("No Cookie")
+	} else if err != nil {
+		panic(err)
 	} else {
-		s = SessionFromCookie(cookie, key)
+		s = SessionFromCookie(cookie, keys...)
+	}
+
+	if store != nil && cookie != nil {
+		if len(s.id) == 0 {
+			panic(fmt.Errorf("Session cookie does not contain a session id."))
+		}
+		p, err := store.Load(s.id)
+		if err != nil {
+			panic(fmt.Errorf("Could not load session: %s", err))
+		} else if p == nil {
+			panic(fmt.Errorf("Session \"%s\" is unknown or has been invalidated.", s.id))
+		}
+		s.Properties = p
 	}
 	return s
 }
@@ -362,7 +498,12 @@ func (c *HTTPContext) CRID() string {
 func NewContainer(args ...Properties) *Container {
 	f := &Container{
 		bindingContainer:       make(bindingContainer),
+		interfaceRoles:         make(map[string][]string),
+		interfaceInterceptors:  make(map[string][]Interceptor),
+		interfaceFilters:       make(map[string][]Filter),
 		globalInjections:       make(Injections),
+		providers:              make(map[reflect.Type]*provider),
+		builtinInjections:      make(map[reflect.Type]bool),
 		converterRegistry:      make(map[reflect.Type]Converter),
 		ServeMux:               http.NewServeMux(),
 		flags:                  F_DEFAULT,
@@ -373,12 +514,18 @@ func NewContainer(args ...Properties) *Container {
 		namespace:              DefaultNamespace,
 		context:                DefaultContext,
 		publicDir:              DefaultFileServerDir,
-		key:                    GenerateKey(16),
+		sessionTTL:             DefaultSessionTTL,
+		cookie:                 NewCookiePolicy(),
 		cache:                  make(map[string]*cache),
 		template:               make(map[string]*template.Template),
 		HTTPContextConstructor: NewHTTPContext,
 		publicContext:          DefaultFileServerContext}
 
+	f.SetKeys(GenerateKey(16))
+
+	jobWorkers, jobQueueSize := DefaultJobWorkers, DefaultJobQueueSize
+	cacheSize := DefaultCacheSize
+
 	f.RegisterConverter("", StringConverter)
 	f.RegisterConverter(time.Now(), TimeConverter)
 
@@ -411,16 +558,74 @@ func NewContainer(args ...Properties) *Container {
 			case P_PUBLICCONTEXT:
 				f.publicContext = v
 			case P_APPLICATIONKEY:
-				f.key = []byte(v)
+				f.SetKeys([]byte(v))
+			case P_COOKIENAME:
+				f.cookie.Name = v
+			case P_COOKIEPATH:
+				f.cookie.Path = v
+			case P_COOKIEDOMAIN:
+				f.cookie.Domain = v
+			case P_COOKIESECURE:
+				f.cookie.Secure = v == "true"
+			case P_COOKIEHTTPONLY:
+				f.cookie.HttpOnly = v == "true"
+			case P_SLIDINGSESSION:
+				f.cookie.Sliding = v == "true"
+			case P_COOKIESAMESITE:
+				if ss, err := ParseSameSite(v); err == nil {
+					f.cookie.SameSite = ss
+				} else {
+					panic(err)
+				}
+			case P_COOKIECHUNKS:
+				if iv, err := strconv.Atoi(v); err != nil || iv <= 0 {
+					panic(fmt.Errorf("Invalid maximum number of cookie chunks: \"%s\".", v))
+				} else {
+					f.cookie.MaxChunks = iv
+				}
+			case P_COOKIEMAXAGE:
+				if d, err := time.ParseDuration(v); err == nil {
+					f.cookie.MaxAge = d
+				} else {
+					panic(fmt.Errorf("Invalid cookie max age: %s", err))
+				}
+			case P_SESSIONTTL:
+				if d, err := time.ParseDuration(v); err != nil || d < 0 {
+					panic(fmt.Errorf("Invalid session ttl: \"%s\".", v))
+				} else {
+					f.sessionTTL = d
+				}
 			case P_FLAGS:
 				if iv, err := strconv.Atoi(v); err != nil {
 					panic(fmt.Errorf("Could not parse initialization flags: %s", err.Error()))
 				} else {
 					f.flags = iv
 				}
+			case P_JOBWORKERS:
+				if iv, err := strconv.Atoi(v); err != nil || iv <= 0 {
+					panic(fmt.Errorf("Invalid amount of job workers: \"%s\".", v))
+				} else {
+					jobWorkers = iv
+				}
+			case P_JOBQUEUESIZE:
+				if iv, err := strconv.Atoi(v); err != nil || iv < 0 {
+					panic(fmt.Errorf("Invalid job queue size: \"%s\".", v))
+				} else {
+					jobQueueSize = iv
+				}
+			case P_CACHESIZE:
+				if iv, err := strconv.Atoi(v); err != nil || iv <= 0 {
+					panic(fmt.Errorf("Invalid response cache size: \"%s\".", v))
+				} else {
+					cacheSize = iv
+				}
 			}
 		}
 	}
+	f.jobs = newJobQueue(jobWorkers, jobQueueSize)
+	f.responseCache = newResponseCache(cacheSize)
+	f.rateLimitStore = NewMemoryRateLimitStore()
+	f.cors = DefaultCORSPolicy.copy()
 
 	// HTTPContext is always available, dummy will never be used
 	f.SetupGlobalInjection(&HTTPContext{})
@@ -438,6 +643,28 @@ func NewContainer(args ...Properties) *Container {
 	var bc *BinaryContent = nil
 	f.SetupGlobalInjection(bc)
 
+	// The job of an asynchronous call. Synchronous calls get a detached job.
+	f.SetupGlobalInjection(newDetachedJob())
+
+	// The authenticated principal may be nil.
+	var p *Principal = nil
+	f.SetupGlobalInjection(p)
+
+	// The claims of the authenticated principal may be nil.
+	f.SetupGlobalInjection(Claims(nil))
+
+	// The api key of the call may be nil.
+	var k *APIKey = nil
+	f.SetupGlobalInjection(k)
+
+	// The path parameters of a route call may be nil.
+	f.SetupGlobalInjection(PathParams(nil))
+
+	// The objects above are only injected by their exact type.
+	for t := range f.globalInjections {
+		f.builtinInjections[t] = true
+	}
+
 	return f
 }
 
@@ -679,6 +906,12 @@ func (b *Container) build(c *HTTPContext, out io.Writer) {
 	url := b.externalUrlFromRequest(c.Request)
 	ckey, baseUrl := b.engineCacheKey(url, p)
 
+	//Each set of roles gets its own engine containing only the allowed bindings.
+	roles := c.Roles(c.Session(b.keys...))
+	if len(roles) > 0 {
+		ckey += "#" + strings.Join(roles, ",")
+	}
+
 	if _, exists := b.cache[ckey]; !exists {
 		b.cache[ckey] = &cache{}
 	}
@@ -706,12 +939,20 @@ func (b *Container) build(c *HTTPContext, out io.Writer) {
 		if (b.flags & F_VALIDATE_ARGS) > 0 {
 			vav = "true"
 		}
+		cred := ""
+		if b.cors != nil && b.cors.Credentials {
+			cred = "true"
+		}
+
 		proxyParams := map[string]string{
 			tokenNamespace:         b.namespace,
 			tokenValidateArguments: vav,
 			tokenHeaderCRID:        DefaultHeaderCRID,
 			tokenContentType:       DefaultMimeType,
 			tokenCRIDLength:        fmt.Sprintf("%d", CRIDLength),
+			tokenInternalInterface: DefaultInternalInterfaceName,
+			tokenCredentials:       cred,
+			tokenHeaderCSRF:        DefaultHeaderCSRF,
 			tokenBaseContext:       baseUrl}
 
 		//TODO: check which params are actually needed here.
@@ -722,13 +963,23 @@ func (b *Container) build(c *HTTPContext, out io.Writer) {
 		// (3) Interface objects
 		interfaces := b.InterfaceNames()
 		for _, in := range interfaces {
+			all := b.bindingContainer.BindingNames(in)
+			methods := make([]string, 0, len(all))
+			for _, m := range all {
+				if bi, _ := b.Binding(in, m); bi.Allowed(roles) {
+					methods = append(methods, m)
+				}
+			}
+			if len(methods) == 0 && len(all) > 0 {
+				continue // hide interfaces without any allowed binding
+			}
+
 			interfaceParams := MapAppend(map[string]string{
 				tokenInterfaceName: in}, proxyParams)
 
 			b.template[p].Lookup(InterfaceTemplate).Execute(minbuf, interfaceParams)
 
 			// (4) Method objects
-			methods := b.bindingContainer.BindingNames(in)
 			for _, m := range methods {
 				bi, _ := b.Binding(in, m)
 				vs := bi.ValidationString()
@@ -742,10 +993,16 @@ func (b *Container) build(c *HTTPContext, out io.Writer) {
 					rbc = "true"
 				}
 
+				async := ""
+				if bi.IsAsync() {
+					async = "true"
+				}
+
 				methodParams := MapAppend(map[string]string{
 					tokenMethodName:      m,
-					tokenHttpMethod:      "POST",
+					tokenHttpMethod:      bi.httpMethod(),
 					tokenHasBinary:       rbc,
+					tokenAsync:           async,
 					tokenArgumentsString: vs}, interfaceParams)
 				b.template[p].Lookup(MethodTemplate).Execute(minbuf, methodParams)
 			}
@@ -783,6 +1040,10 @@ func (f *Container) Context(args ...string) string {
 		if !strings.HasSuffix(f.context, "/") {
 			f.context = f.context + "/"
 		}
+
+		for _, m := range f.mounts {
+			f.attach(m)
+		}
 	}
 
 	return f.context[:len(f.context)-1]
@@ -843,12 +1104,12 @@ func (f *Container) Setup(args ...string) (handler http.Handler) {
 		f.Context(args[1])
 	}
 
+	f.checkAllProviders()
+
 	// Setup gotojs engine handler.
 	log.Printf("GotojsEngine enabled at '%s'", f.context)
 
-	f.HandleFunc(f.context, func(res http.ResponseWriter, req *http.Request) {
-		f.serveHTTP(res, req)
-	})
+	f.HandleFunc(f.context, f.serve)
 
 	if f.flags&F_ENABLE_ACCESSLOG > 0 {
 		handler = NewlogWrapper(f)
@@ -871,10 +1132,19 @@ func (f *Container) Setup(args ...string) (handler http.Handler) {
 // 1st optional parameter is the listen address ("localhost:8080") and 2nd optional parmaeter is
 // the engine context ("/gotojs")
 // If these are not provided, default or initialization values are used
+// The installed modules are started before the server. Use Stop to shut down the server. If the
+// server fails, the modules are stopped again.
 func (f *Container) Start(args ...string) error {
 	_ = f.Setup(args...)
+	if err := f.StartModules(); err != nil {
+		return err
+	}
 	log.Printf("Starting server at \"%s\".", f.addr)
-	return f.httpd.ListenAndServe()
+	err := f.httpd.ListenAndServe()
+	if err != http.ErrServerClosed {
+		f.stopAllModules()
+	}
+	return err
 }
 
 // Redirect is a convenience method which configures a redirect handler from the patter to adestination url.
@@ -954,6 +1224,14 @@ func (b *Container) ExposeRemoteBinding(u, rin, rmn, signature, lin, lfn string)
 	return pm.S()
 }
 
+// serve is the handler of the gotojs context and the routes. It passes the request through
+// the CORS and idempotency handling to serveHTTP.
+func (f *Container) serve(w http.ResponseWriter, r *http.Request) {
+	if !f.serveCORS(w, r) {
+		f.serveIdempotent(w, r)
+	}
+}
+
 // serveHTTP processes http request. The behaviour depends on the path and method of the call ass follows:
 //	"POST": regular binding call. Interface and method name as well as parameter
 //		are expected in the body of the POST call as a JSON object.
@@ -970,7 +1248,9 @@ func (f *Container) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	defer func() {
 		w.Header().Set(CTHeader, mt)
 		w.Header().Set(DefaultHeaderCRID, crid)
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if f.cors != nil {
+			f.cors.apply(w, r)
+		}
 		// we recover here because, we want to give a proper HTTP response whatever happens.
 		if re := recover(); re != nil {
 			//TODO: Create a HTTPErrorf() (besiedes httpcontest.Errorf() )
@@ -1001,10 +1281,12 @@ func (f *Container) serveHTTP(w http.ResponseWriter, r *http.Request) {
 		crid = DefaultCRID
 	}
 
-	session := httpContext.Session(f.key)
+	session := httpContext.Session(f.keys...)
 
 	defer session.Flush(w, f.key) //Update session on client side if necessary.
 
+	httpContext.Principal = f.authenticate(httpContext)
+
 	path := r.URL.Path
 	if strings.HasPrefix(path, f.context) {
 		sub := strings.SplitAfterN(path, f.context, 2)
@@ -1016,35 +1298,12 @@ func (f *Container) serveHTTP(w http.ResponseWriter, r *http.Request) {
 				args := SAToIA(elems[2:]...)
 
 				//Check if the query string contains parameters
-				if vals, err := url.ParseQuery(r.URL.RawQuery); err == nil {
-					for _, v := range vals {
-						args = append(args, SAToIA(v...)...)
-					}
-				}
-
-				//Parameter in json body are only accepted for POST calls and ContentType "application/json"
-				if ct := httpContext.Request.Header.Get(CTHeader); strings.HasPrefix(ct, DefaultMimeType) && r.Method == "POST" {
-					dec := json.NewDecoder(r.Body)
-					var i []interface{}
-					if e := dec.Decode(&i); e != nil {
-						panic(e)
-					}
-
-					args = append(args, i...)
-				}
+				args = append(args, f.queryArgs(r)...)
 
-				vs := b.ValidationString()
-				if len(vs) != len(args) {
-					httpContext.Errorf(http.StatusBadRequest, "Invalid parameter count: %d/%d (%s)%s", len(args), len(vs), vs, args)
-					return
-				}
+				//Parameter in json body are only accepted for non GET calls and ContentType "application/json"
+				args = append(args, bodyArgs(r, false)...)
 
-				switch r.Method {
-				case "GET":
-					mt = b.processCall(obuf, NewI(httpContext, session), args...)
-				default:
-					mt = b.processCall(obuf, NewI(httpContext, session, NewBinaryContent(r)), args...)
-				}
+				mt = f.dispatch(httpContext, session, obuf, b, args)
 			} else {
 				httpContext.Errorf(http.StatusNotFound, "Binding %s.%s not found.", elems[0], elems[1])
 			}
@@ -1052,7 +1311,13 @@ func (f *Container) serveHTTP(w http.ResponseWriter, r *http.Request) {
 			log.Printf("Sending Engine.")
 			mt = "application/javascript"
 			f.build(httpContext, obuf)
+			f.writeCSRF(obuf, r, session)
+			f.buildMounts(httpContext, obuf)
 		}
+	} else if rt, params := f.matchRoute(httpContext); rt != nil {
+		args := append(params.args(rt), f.queryArgs(r)...)
+		args = append(args, bodyArgs(r, true)...)
+		mt = f.dispatch(httpContext, session, obuf, rt.Binding, args, params)
 	} else {
 		//Not Gotojs context
 		httpContext.Errorf(http.StatusNotFound, "Not within gotojs context.")
@@ -1060,6 +1325,88 @@ func (f *Container) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// queryArgs returns the call parameters taken from the query string.
+func (f *Container) queryArgs(r *http.Request) (args []interface{}) {
+	if vals, err := url.ParseQuery(r.URL.RawQuery); err == nil {
+		for k, v := range vals {
+			if k == DefaultAPIKeyParam && f.apiKeys != nil {
+				continue
+			}
+			args = append(args, SAToIA(v...)...)
+		}
+	}
+	return
+}
+
+// bodyArgs returns the call parameters taken from a JSON body. The body is expected to be an array
+// of parameters. If single is set, any other JSON value is accepted as single parameter.
+func bodyArgs(r *http.Request, single bool) []interface{} {
+	if ct := r.Header.Get(CTHeader); !strings.HasPrefix(ct, DefaultMimeType) || r.Method == "GET" {
+		return nil
+	}
+
+	var i interface{}
+	if e := json.NewDecoder(r.Body).Decode(&i); e != nil && e != io.EOF {
+		panic(e)
+	}
+
+	switch v := i.(type) {
+	case []interface{}:
+		return v
+	case nil:
+		return nil
+	}
+	if !single {
+		panic(fmt.Errorf("Parameters must be passed as JSON array."))
+	}
+	return []interface{}{i}
+}
+
+// dispatch invokes the binding by the given http request and returns the mime type of the result.
+func (f *Container) dispatch(httpContext *HTTPContext, session *Session, obuf *bytes.Buffer, b Binding, args []interface{}, extra ...interface{}) (mt string) {
+	r := httpContext.Request
+	apiKey := f.apiKey(httpContext)
+
+	vs := b.ValidationString()
+	if len(vs) != len(args) {
+		httpContext.Errorf(http.StatusBadRequest, "Invalid parameter count: %d/%d (%s)%s", len(args), len(vs), vs, args)
+	}
+
+	var claims Claims
+	if httpContext.Principal != nil {
+		claims = httpContext.Principal.Claims
+	}
+
+	injs := NewI(append([]interface{}{httpContext, session, httpContext.Principal, claims, apiKey}, extra...)...)
+	if r.Method != "GET" {
+		injs.Add(NewBinaryContent(r))
+	}
+	if f.sessionType != nil {
+		injs.Add(session.payloadOf(f.sessionType))
+	}
+
+	b.guard(httpContext, injs)
+
+	if b.IsAsync() {
+		return b.processAsync(httpContext, obuf, injs, args...)
+	} else if b.base().cachePolicy != nil && r.Method == "GET" {
+		return b.processCached(httpContext, obuf, injs, args...)
+	}
+	return b.processCall(obuf, injs, args...)
+}
+
+// guard enforces the access policies of a binding before it is invoked via HTTP. It aborts the
+// call by an HTTP error if a policy is violated.
+func (b Binding) guard(hc *HTTPContext, injs Injections) {
+	s := injs[typeOfSession].(*Session)
+	b.checkMethod(hc)
+	b.authorize(hc)
+	b.permit(hc, s)
+	b.checkCSRF(hc, s)
+	b.meter(hc, injs)
+	b.limit(hc, injs)
+}
+
 //Url retrieves the actuall HTTP Url to access this binding directly.
 func (b Binding) Url() (ret *url.URL) {
 	bu := b.base().container.BaseUrl()
@@ -1076,10 +1423,14 @@ func (b Binding) Url() (ret *url.URL) {
 // Internally used method to process a call. Input parameters, interface name and method name are read from a JSON encoded
 // input stream. The result is encoded to a JSON output stream.
 func (f Binding) processCall(out io.Writer, injs Injections, args ...interface{}) (mime string) {
-	var err error
 	//defer func() { Log("CALL", "-", f.Name()) }()
-	ret := f.InvokeI(injs, args...)
+	return encodeResult(out, f.InvokeI(injs, args...))
+}
 
+// encodeResult writes the return value of a call to the output stream. Binary results are
+// copied untouched, all others are JSON encoded.
+func encodeResult(out io.Writer, ret interface{}) (mime string) {
+	var err error
 	if bin, ok := ret.(Binary); ok {
 		defer bin.Close()
 		mime = bin.MimeType()
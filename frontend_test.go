@@ -330,6 +330,41 @@ func TestSession(t *testing.T) {
 	}
 }
 
+func TestSessionExpiry(t *testing.T) {
+	key := GenerateKey(16)
+	s := NewSession()
+	s.issued, s.ttl = time.Now().Add(-2*time.Hour), time.Hour
+	s.Set("testkey", "testval")
+	c := s.Cookie("gotojs", "/", key)
+
+	hc := &HTTPContext{}
+	hc.Request, _ = http.NewRequest("GET", "http://localhost:666/Ignoreme", nil)
+	hc.Request.AddCookie(c)
+	if hc.Session(key).Get("testkey") != "" {
+		t.Errorf("Expired session has been accepted.")
+	}
+}
+
+func TestSessionKeyRotation(t *testing.T) {
+	co := NewContainer()
+	s := NewSession()
+	s.Set("testkey", "testval")
+	c := s.Cookie("gotojs", "/", co.key)
+
+	co.RotateKey(GenerateKey(16))
+	if SessionFromCookie(c, co.keys...).Get("testkey") != "testval" {
+		t.Errorf("Session of a previous key has been rejected.")
+	}
+
+	co.SetKeys(co.key)
+	hc := &HTTPContext{}
+	hc.Request, _ = http.NewRequest("GET", "http://localhost:666/Ignoreme", nil)
+	hc.Request.AddCookie(c)
+	if hc.Session(co.keys...).Get("testkey") != "" {
+		t.Errorf("Session of a removed key has been accepted.")
+	}
+}
+
 func TestInvalidSession(t *testing.T) {
 	key := GenerateKey(16)
 	c := &HTTPContext{}
@@ -28,6 +28,12 @@ func ContextInjector(b Binding, hc *HTTPContext, injs Injections) bool {
 	return true
 }
 
+// ContextProvider is a gotojs provider function that creates the GAE context only for bindings
+// declaring it as injection argument. It is registered by Container.Provide(ContextProvider).
+func ContextProvider(hc *HTTPContext) *Context {
+	return NewContext(hc)
+}
+
 //NewContext creates a new appengine context wrapper by the given http call attributes.
 func NewContext(hc *HTTPContext) *Context {
 	c := appengine.NewContext(hc.Request)
@@ -4,13 +4,12 @@ package gotojs
 //package or repository.
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"log"
-	"math/rand"
 	"runtime"
 	"strconv"
 	"strings"
@@ -41,63 +40,75 @@ func Log(t string, args ...string) {
 	log.Printf("[%s|%d]%s", t, runtime.NumGoroutine(), strings.Join(args, "\t"))
 }
 
-// GenerateKey generates a random application key.
+// GenerateKey generates a random application key using a cryptographically secure source.
 func GenerateKey(size int) (ba []byte) {
-	r := rand.New(rand.NewSource(time.Now().Unix()))
 	ba = make([]byte, size)
-
-	for i := 0; i < size; i++ {
-		ba[i] = byte(r.Intn(256))
+	if _, err := io.ReadFull(rand.Reader, ba); err != nil {
+		panic(fmt.Errorf("Could not generate key: %s", err))
 	}
 	return
 }
 
-// encrypt encrypts an input string using the given key.
-func Encrypt(in, key []byte) []byte {
-	ibuf := bytes.NewBuffer(in)
-	obuf := new(bytes.Buffer)
-
+// newGCM creates an AES-GCM AEAD for the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	return cipher.NewGCM(block)
+}
 
-	// If the key is unique for each ciphertext, then it's ok to use a zero
-	// IV.
-	var iv [aes.BlockSize]byte
-	stream := cipher.NewOFB(block, iv[:])
-
-	writer := &cipher.StreamWriter{S: stream, W: obuf}
-
-	if _, err := io.Copy(writer, ibuf); err != nil {
-		panic(err)
+// Seal encrypts and authenticates the input using AES-GCM. A random nonce is generated
+// for each call and prepended to the output.
+func Seal(in, key []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
 	}
 
-	return obuf.Bytes()
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(in)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, in, nil), nil
 }
 
-// decrypt decrypts an encrypted input string.
-func Decrypt(in, key []byte) []byte {
-	ibuf := bytes.NewBuffer(in)
-	obuf := new(bytes.Buffer)
+// Open decrypts and verifies an input that has been sealed by Seal. The given keys are tried
+// in order which allows to accept input sealed by previous keys.
+func Open(in []byte, keys ...[]byte) ([]byte, error) {
+	err := fmt.Errorf("No key given.")
+	for _, key := range keys {
+		var aead cipher.AEAD
+		if aead, err = newGCM(key); err != nil {
+			continue
+		}
+		if len(in) < aead.NonceSize() {
+			return nil, fmt.Errorf("Sealed input too short.")
+		}
+		var out []byte
+		if out, err = aead.Open(nil, in[:aead.NonceSize()], in[aead.NonceSize():], nil); err == nil {
+			return out, nil
+		}
+	}
+	return nil, err
+}
 
-	block, err := aes.NewCipher(key)
+// Encrypt encrypts an input string using the given key. See Seal.
+func Encrypt(in, key []byte) []byte {
+	out, err := Seal(in, key)
 	if err != nil {
 		panic(err)
 	}
+	return out
+}
 
-	// If the key is unique for each ciphertext, then it's ok to use a zero
-	// IV.
-	var iv [aes.BlockSize]byte
-	stream := cipher.NewOFB(block, iv[:])
-
-	reader := &cipher.StreamReader{S: stream, R: ibuf}
-
-	if _, err := io.Copy(obuf, reader); err != nil {
+// Decrypt decrypts an encrypted input string using the given keys. See Open.
+func Decrypt(in []byte, keys ...[]byte) []byte {
+	out, err := Open(in, keys...)
+	if err != nil {
 		panic(err)
 	}
-
-	return obuf.Bytes()
+	return out
 }
 
 //sToIArray is an string var args to interface{} array converter.
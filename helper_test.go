@@ -56,6 +56,30 @@ func TestEncryption(t *testing.T) {
 	}
 }
 
+func TestSeal(t *testing.T) {
+	oldKey, newKey := GenerateKey(16), GenerateKey(32)
+	in := []byte("secret content")
+
+	a, _ := Seal(in, oldKey)
+	b, _ := Seal(in, oldKey)
+	if bytes.Equal(a, b) {
+		t.Errorf("Nonce has been reused.")
+	}
+
+	if out, err := Open(a, newKey, oldKey); err != nil || !bytes.Equal(out, in) {
+		t.Errorf("Content sealed by a previous key could not be opened: %s", err)
+	}
+
+	a[len(a)-1] ^= 1
+	if _, err := Open(a, oldKey); err == nil {
+		t.Errorf("Tampered content has been accepted.")
+	}
+
+	if _, err := Open(b, newKey); err == nil {
+		t.Errorf("Content has been opened by a wrong key.")
+	}
+}
+
 func TestReaderArray(t *testing.T) {
 	a := bytes.NewBufferString("-A-")
 	b := bytes.NewBufferString("-B-")
@@ -0,0 +1,265 @@
+package gotojs
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default values of the idempotent retry handling.
+const (
+	DefaultIdempotencyWindow   = 10 * time.Minute
+	DefaultIdempotencyCapacity = 10000
+	DefaultIdempotencyMaxBody  = 1 << 20  // in bytes per recorded response
+	DefaultIdempotencyMaxSize  = 64 << 20 // in bytes of all recorded responses
+	DefaultHeaderReplay        = "x-gotojs-replay"
+)
+
+// recordedResponse is a response that has been recorded for a CRID. It is replayed for
+// retries of the same call.
+type recordedResponse struct {
+	key     string
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+	done    chan struct{}
+
+	// discarded is set if the response has not been recorded. Retries have to be processed again.
+	discarded bool
+}
+
+// responseRecorder is a http.ResponseWriter that passes the response to the origin writer
+// and records it at the same time. Bodies exceeding the limit are not recorded.
+type responseRecorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	limit    int
+	overflow bool
+}
+
+// WriteHeader records the status code and passes it to the origin writer.
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body and passes it to the origin writer.
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if !r.overflow {
+		if r.body.Len()+len(p) > r.limit {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(p)
+		}
+	}
+	return r.ResponseWriter.Write(p)
+}
+
+// idempotencyStore remembers the responses of recent calls by their CRID for a given window.
+// It keeps at most capacity responses of at most maxSize bytes in total. If exceeded, the oldest
+// ones are dropped. Responses larger than maxBody bytes are not kept at all.
+type idempotencyStore struct {
+	window    time.Duration
+	capacity  int
+	maxBody   int
+	maxSize   int
+	size      int
+	responses map[string]*list.Element
+	order     *list.List
+	mutex     sync.Mutex
+}
+
+// newIdempotencyStore creates a new store which keeps up to capacity responses for the given window.
+func newIdempotencyStore(window time.Duration, capacity int) *idempotencyStore {
+	return &idempotencyStore{
+		window:    window,
+		capacity:  capacity,
+		maxBody:   DefaultIdempotencyMaxBody,
+		maxSize:   DefaultIdempotencyMaxSize,
+		responses: make(map[string]*list.Element),
+		order:     list.New()}
+}
+
+// remove drops a response from the store. The store must be locked.
+func (s *idempotencyStore) remove(el *list.Element) {
+	rr := el.Value.(*recordedResponse)
+	s.order.Remove(el)
+	delete(s.responses, rr.key)
+	s.size -= len(rr.body)
+}
+
+// expire removes all responses that exceeded the window. The store must be locked.
+func (s *idempotencyStore) expire() {
+	now := time.Now()
+	for el := s.order.Front(); el != nil; el = s.order.Front() {
+		rr := el.Value.(*recordedResponse)
+		if rr.expires.After(now) {
+			return
+		}
+		s.remove(el)
+	}
+}
+
+// evict removes the oldest responses until a new one fits into the store. The store must be
+// locked.
+func (s *idempotencyStore) evict() {
+	for s.order.Len() >= s.capacity && s.order.Len() > 0 {
+		s.remove(s.order.Front())
+	}
+}
+
+// acquire looks up the response of the given key. If none exists, a new pending one is
+// registered and returned with found set to false.
+func (s *idempotencyStore) acquire(key string) (rr *recordedResponse, found bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.expire()
+	if el, found := s.responses[key]; found {
+		return el.Value.(*recordedResponse), true
+	}
+	s.evict()
+	rr = &recordedResponse{
+		key:     key,
+		expires: time.Now().Add(s.window),
+		done:    make(chan struct{})}
+	s.responses[key] = s.order.PushBack(rr)
+	return rr, false
+}
+
+// complete records the response of the given recorder. Responses of failed or rate limited
+// calls and responses exceeding the maximum body size are discarded, so that retries are
+// processed again. The oldest responses are dropped if the store exceeds its maximum size.
+func (s *idempotencyStore) complete(rr *recordedResponse, rec *responseRecorder) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if status := rec.status; status >= http.StatusInternalServerError || status == http.StatusTooManyRequests || rec.overflow {
+		if el, found := s.responses[rr.key]; found && el.Value == rr {
+			s.remove(el)
+		}
+		rr.discarded = true
+		close(rr.done)
+		return
+	}
+
+	rr.record(rec)
+	if _, found := s.responses[rr.key]; found {
+		s.size += len(rr.body)
+	}
+	for s.size > s.maxSize && s.order.Len() > 0 {
+		s.remove(s.order.Front())
+	}
+}
+
+// replay writes a recorded response to the given writer. It waits until the response is
+// complete and returns false if it has been discarded.
+func (rr *recordedResponse) replay(w http.ResponseWriter) bool {
+	<-rr.done
+	if rr.discarded {
+		return false
+	}
+	h := w.Header()
+	for k, v := range rr.header {
+		h[k] = v
+	}
+	h.Set(DefaultHeaderReplay, "true")
+	w.WriteHeader(rr.status)
+	w.Write(rr.body)
+	return true
+}
+
+// record completes the recorded response. Cookies are not recorded since they must not be
+// handed out twice.
+func (rr *recordedResponse) record(rec *responseRecorder) {
+	rr.status = rec.status
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	rr.header = make(http.Header)
+	for k, v := range rec.Header() {
+		switch k {
+		case "Date", "Set-Cookie":
+		default:
+			rr.header[k] = v
+		}
+	}
+	rr.body = rec.body.Bytes()
+	close(rr.done)
+}
+
+// EnableIdempotency enables the deduplication of retried POST calls. Responses are remembered
+// by the CRID of the call and the caller for the given window. A retry of the same caller with
+// the same CRID is answered with the original response instead of invoking the binding again.
+// At most capacity responses are remembered. If window or capacity is 0,
+// DefaultIdempotencyWindow or DefaultIdempotencyCapacity is used.
+func (f *Container) EnableIdempotency(window time.Duration, capacity int) {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	if capacity <= 0 {
+		capacity = DefaultIdempotencyCapacity
+	}
+	f.idempotency = newIdempotencyStore(window, capacity)
+}
+
+// caller identifies the caller of the request by its sealed session cookie and credentials.
+// Callers without a session and credentials are identified by their remote address.
+func (f *Container) caller(r *http.Request) string {
+	var id []byte
+	if c, _, err := f.cookie.join(r); err == nil {
+		id = append(id, "session:"+c.Value+"\n"...)
+	}
+	if a := r.Header.Get("Authorization"); len(a) > 0 {
+		id = append(id, "authorization:"+a+"\n"...)
+	}
+	if k := requestAPIKey(r); len(k) > 0 {
+		id = append(id, "apikey:"+k+"\n"...)
+	}
+	if len(id) == 0 {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		id = append(id, "remote:"+host...)
+	}
+
+	sum := sha256.Sum256(id)
+	return hex.EncodeToString(sum[:])
+}
+
+// serveIdempotent processes a http request with respect to retried calls. Only POST calls with
+// a valid CRID are considered. Responses are only replayed to the caller of the original call.
+// Responses with status 429 or 5xx are not replayed, so retries are processed again.
+func (f *Container) serveIdempotent(w http.ResponseWriter, r *http.Request) {
+	crid := r.Header.Get(DefaultHeaderCRID)
+	if f.idempotency == nil || r.Method != "POST" || len(crid) == 0 || crid == DefaultCRID {
+		f.serveHTTP(w, r)
+		return
+	}
+
+	rr, found := f.idempotency.acquire(crid + " " + r.URL.Path + " " + f.caller(r))
+	if found {
+		if rr.replay(w) {
+			r.Body.Close()
+		} else {
+			f.serveHTTP(w, r)
+		}
+		return
+	}
+
+	rec := &responseRecorder{ResponseWriter: w, limit: f.idempotency.maxBody}
+	defer f.idempotency.complete(rr, rec)
+	f.serveHTTP(rec, r)
+}
@@ -0,0 +1,170 @@
+package gotojs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdempotentRetry(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.EnableIdempotency(time.Minute, 0)
+	count := 0
+	co.ExposeFunction(func(i int) int {
+		count += i
+		return count
+	}, "Counter", "Add")
+	h := co.Setup()
+
+	post := func(crid string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/gotojs/Counter/Add", bytes.NewBufferString("[5]"))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		req.Header.Set(DefaultHeaderCRID, crid)
+		return record(h, req)
+	}
+
+	r1 := post("ABC.1")
+	r2 := post("ABC.1")
+	if count != 5 {
+		t.Errorf("Retried call has been executed twice: %d/%d", count, 5)
+	}
+
+	if r2.Body.String() != r1.Body.String() || r2.Code != r1.Code {
+		t.Errorf("Retry did not return the original response: '%s'/'%s'", r2.Body.String(), r1.Body.String())
+	}
+
+	if r1.Header().Get(DefaultHeaderReplay) != "" || r2.Header().Get(DefaultHeaderReplay) != "true" {
+		t.Errorf("Replay header not set correctly.")
+	}
+
+	post("ABC.2")
+	if count != 10 {
+		t.Errorf("Call with different CRID has not been executed: %d/%d", count, 10)
+	}
+
+	req := httptest.NewRequest("GET", "/gotojs/Counter/Add/1", nil)
+	req.Header.Set(DefaultHeaderCRID, "ABC.2")
+	if r := record(h, req); r.Code != http.StatusOK || count != 11 {
+		t.Errorf("GET calls must not be deduplicated: %d/%d", count, 11)
+	}
+}
+
+func TestIdempotencyWindow(t *testing.T) {
+	s := newIdempotencyStore(time.Millisecond, 10)
+	rr, found := s.acquire("A")
+	if found {
+		t.Errorf("Unknown key has been found.")
+	}
+	rr.record(&responseRecorder{ResponseWriter: httptest.NewRecorder()})
+
+	if _, found = s.acquire("A"); !found {
+		t.Errorf("Recorded response has not been found.")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if _, found = s.acquire("A"); found {
+		t.Errorf("Expired response has been found.")
+	}
+}
+
+func TestIdempotencyCapacity(t *testing.T) {
+	s := newIdempotencyStore(time.Minute, 2)
+	for _, k := range []string{"A", "B", "C"} {
+		s.acquire(k)
+	}
+	if _, found := s.acquire("A"); found {
+		t.Errorf("Oldest response has not been evicted.")
+	}
+	if len(s.responses) != 2 {
+		t.Errorf("Store exceeds its capacity: %d", len(s.responses))
+	}
+}
+
+func TestIdempotencyCaller(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.EnableIdempotency(time.Minute, 0)
+	count := 0
+	co.ExposeFunction(func(s *Session, v string) int {
+		count++
+		s.Set("v", v)
+		return count
+	}, "Secret", "Set")
+	h := co.Setup()
+
+	post := func(crid string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/gotojs/Secret/Set", bytes.NewBufferString(`["x"]`))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		req.Header.Set(DefaultHeaderCRID, crid)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return record(h, req)
+	}
+
+	alice := post("A.1").Result().Cookies()
+	bob := post("B.1").Result().Cookies()
+	if count != 2 || len(alice) != 1 || len(bob) != 1 {
+		t.Fatalf("Sessions have not been created: %d", count)
+	}
+
+	post("A.2", alice[0])
+	r := post("A.2", bob[0])
+	if count != 4 || r.Header().Get(DefaultHeaderReplay) != "" {
+		t.Errorf("Response of another session has been replayed: %d", count)
+	}
+
+	if r = post("A.2", alice[0]); count != 4 || r.Header().Get(DefaultHeaderReplay) != "true" {
+		t.Errorf("Retry of the same session has not been replayed: %d", count)
+	}
+	if len(r.Result().Cookies()) != 0 {
+		t.Errorf("Session cookie has been replayed: %v", r.Result().Cookies())
+	}
+}
+
+func TestIdempotencyDiscard(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.EnableIdempotency(time.Minute, 0)
+	co.idempotency.maxBody = 16
+	count := 0
+	co.ExposeFunction(func(hc *HTTPContext, s string) string {
+		count++
+		if count == 1 {
+			hc.Errorf(http.StatusServiceUnavailable, "Unavailable on purpose.")
+		}
+		return s
+	}, "Flaky", "Echo")
+	h := co.Setup()
+
+	post := func(crid, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/gotojs/Flaky/Echo", bytes.NewBufferString(body))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		req.Header.Set(DefaultHeaderCRID, crid)
+		return record(h, req)
+	}
+
+	if r := post("A.1", `["x"]`); r.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Unexpected status of failing call: %d", r.Code)
+	}
+	if r := post("A.1", `["x"]`); r.Code != http.StatusOK || count != 2 {
+		t.Errorf("Retry of failed call has not been processed again: %d (%d)", r.Code, count)
+	}
+
+	post("A.2", `["this response exceeds the limit"]`)
+	if r := post("A.2", `["this response exceeds the limit"]`); count != 4 || r.Header().Get(DefaultHeaderReplay) != "" {
+		t.Errorf("Oversized response has been replayed: %d", count)
+	}
+
+	s := newIdempotencyStore(time.Minute, 10)
+	s.maxSize = 10
+	for _, k := range []string{"A", "B"} {
+		rr, _ := s.acquire(k)
+		rec := &responseRecorder{ResponseWriter: httptest.NewRecorder(), limit: s.maxBody}
+		rec.Write([]byte("123456"))
+		s.complete(rr, rec)
+	}
+	if _, found := s.acquire("A"); found || s.size > s.maxSize {
+		t.Errorf("Store exceeds its maximum size: %d/%d", s.size, s.maxSize)
+	}
+}
@@ -0,0 +1,113 @@
+package gotojs
+
+import (
+	"fmt"
+)
+
+// Interceptor wraps the invocation of a binding. It may inspect and replace the arguments before
+// it proceeds with the invocation, and inspect or replace the result and the error afterwards.
+// It may also answer the invocation without proceeding.
+type Interceptor func(inv *Invocation) (interface{}, error)
+
+// Invocation is the call of a binding passed through its interceptors.
+type Invocation struct {
+	Binding    Binding
+	Args       []interface{}
+	Injections Injections
+
+	chain []Interceptor
+	index int
+}
+
+// Proceed calls the next interceptor or finally the binding itself. A panic raised by the
+// binding is returned as error. Errors raised by HTTPContext.Errorf keep their status as long as
+// they are passed on. If an interceptor maps the error to a result, the error state of the HTTP
+// context is reset.
+func (inv *Invocation) Proceed() (ret interface{}, err error) {
+	if inv.index < len(inv.chain) {
+		i := inv.chain[inv.index]
+		inv.index++
+		defer func() { inv.index-- }()
+		return i(inv)
+	}
+
+	defer func() {
+		if re := recover(); re != nil {
+			if e, ok := re.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%s", re)
+			}
+		}
+	}()
+	return inv.Binding.call(inv.Injections, inv.Args), nil
+}
+
+// Intercept adds interceptors to all bindings of the container including those exposed later on.
+// Container interceptors wrap the ones of the interfaces, which wrap the ones of the bindings.
+// Interceptors of the same level are called in the order they have been added.
+func (b *Container) Intercept(is ...Interceptor) {
+	b.interceptors = append(b.interceptors, is...)
+}
+
+// InterceptInterface adds interceptors to all bindings of the named interface including those
+// exposed later on.
+func (b *Container) InterceptInterface(in string, is ...Interceptor) {
+	b.interfaceInterceptors[in] = append(b.interfaceInterceptors[in], is...)
+}
+
+// Intercept adds interceptors to the binding.
+func (b Binding) Intercept(is ...Interceptor) Binding {
+	bb := b.base()
+	bb.interceptors = append(bb.interceptors, is...)
+	return b
+}
+
+// Intercept adds interceptors to the given bindings.
+func (bs Bindings) Intercept(is ...Interceptor) Bindings {
+	for _, b := range bs {
+		b.Intercept(is...)
+	}
+	return bs
+}
+
+// Interceptors returns the interceptors of the binding in the order they are called.
+func (b Binding) Interceptors() (ret []Interceptor) {
+	bb := b.base()
+	ret = append(ret, bb.container.interceptors...)
+	ret = append(ret, bb.container.interfaceInterceptors[bb.interfaceName]...)
+	return append(ret, bb.interceptors...)
+}
+
+// intercept calls the binding through its interceptors. An error returned by the outermost
+// interceptor is raised as panic like any failing call.
+func (b Binding) intercept(inj Injections, args []interface{}) interface{} {
+	chain := b.Interceptors()
+	if len(chain) == 0 {
+		return b.call(inj, args)
+	}
+
+	// Keep the error state of the HTTP context in order to reset it if an error is mapped.
+	hc, _ := inj[typeOfHTTPContext].(*HTTPContext)
+	var status int
+	var header string
+	if hc != nil && hc.Response != nil {
+		status, header = hc.ErrorStatus, hc.Response.Header().Get(DefaultHeaderError)
+	}
+
+	inv := &Invocation{Binding: b, Args: args, Injections: inj, chain: chain}
+	ret, err := inv.Proceed()
+	if err != nil {
+		panic(err)
+	}
+
+	if hc != nil && hc.Response != nil {
+		hc.ErrorStatus = status
+		if len(header) > 0 {
+			hc.Response.Header().Set(DefaultHeaderError, header)
+		} else {
+			hc.Response.Header().Del(DefaultHeaderError)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,93 @@
+package gotojs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	co := NewContainer()
+	var trace []string
+	tracer := func(name string) Interceptor {
+		return func(inv *Invocation) (interface{}, error) {
+			trace = append(trace, name)
+			ret, err := inv.Proceed()
+			trace = append(trace, "/"+name)
+			return ret, err
+		}
+	}
+
+	co.Intercept(tracer("global"))
+	co.InterceptInterface("Calc", tracer("interface"))
+	co.ExposeFunction(func(a, b int) int { return a + b }, "Calc", "Add").Intercept(tracer("binding"))
+	co.ExposeFunction(func(a int) int {
+		if a == 0 {
+			panic(errors.New("division by zero"))
+		}
+		return 100 / a
+	}, "Calc", "Div").Intercept(func(inv *Invocation) (interface{}, error) {
+		ret, err := inv.Proceed()
+		if err != nil {
+			return -1, nil // Map the error.
+		}
+		return ret, nil
+	})
+	co.Intercept(func(inv *Invocation) (interface{}, error) {
+		if inv.Binding.Name() == "Calc.Add" {
+			inv.Args[1] = 10 // Replace an argument.
+		}
+		ret, err := inv.Proceed()
+		if i, ok := ret.(int); ok && i > 1000 {
+			return nil, errors.New("result too large")
+		}
+		return ret, err
+	})
+
+	if res := co.Invoke("Calc", "Add", 1, 2); res != 11 {
+		t.Errorf("Argument has not been replaced: %v", res)
+	}
+
+	if s := strings.Join(trace, ","); s != "global,interface,binding,/binding,/interface,/global" {
+		t.Errorf("Unexpected interceptor order: %s", s)
+	}
+
+	if res := co.Invoke("Calc", "Div", 0); res != -1 {
+		t.Errorf("Error has not been mapped: %v", res)
+	}
+
+	func() {
+		defer func() {
+			if re := recover(); re == nil || !strings.Contains(re.(error).Error(), "too large") {
+				t.Errorf("Error of an interceptor has not been raised: %v", re)
+			}
+		}()
+		co.ExposeFunction(func() int { return 5000 }, "Calc", "Large")
+		co.Invoke("Calc", "Large")
+	}()
+}
+
+func TestInterceptorMapsHTTPError(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(hc *HTTPContext, id string) string {
+		hc.Errorf(http.StatusNotFound, "Item %s not found.", id)
+		return ""
+	}, "Items", "Get").Intercept(func(inv *Invocation) (interface{}, error) {
+		if ret, err := inv.Proceed(); err == nil || inv.Args[0] != "fallback" {
+			return ret, err
+		}
+		return "default", nil
+	})
+	h := co.Setup()
+
+	r := record(h, httptest.NewRequest("GET", "/gotojs/Items/Get/fallback", nil))
+	if r.Code != http.StatusOK || r.Body.String() != `"default"` || len(r.Header().Get(DefaultHeaderError)) > 0 {
+		t.Errorf("Mapped error left an error state: %d %s '%s'", r.Code, r.Body.String(), r.Header().Get(DefaultHeaderError))
+	}
+
+	if r := record(h, httptest.NewRequest("GET", "/gotojs/Items/Get/1", nil)); r.Code != http.StatusNotFound {
+		t.Errorf("Status of a passed error has been lost: %d", r.Code)
+	}
+}
@@ -0,0 +1,396 @@
+package gotojs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Job states as reported by the job status endpoint.
+const (
+	JobPending  = "pending"
+	JobRunning  = "running"
+	JobDone     = "done"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+)
+
+// Default values of the asynchronous job processing.
+const (
+	DefaultJobWorkers   = 4
+	DefaultJobQueueSize = 100
+	DefaultJobRetention = time.Hour
+	jobIDLength         = 16
+)
+
+// Job represents a single asynchronous invocation of a binding that has been marked
+// as async. A job object will be injected whenever a binding declares a parameter of
+// type *Job. This way long-running bindings can report their progress and react on
+// cancellation requests. The HTTP context and session injected into a job are copies
+// of the ones of the submitting request.
+type Job struct {
+	id       string
+	state    string
+	progress float64
+	err      string
+	result   interface{}
+	created  time.Time
+	started  time.Time
+	finished time.Time
+	canceled bool
+	cancel   chan struct{}
+	binding  Binding
+	inj      Injections
+	args     []interface{}
+	mutex    sync.Mutex
+}
+
+// JobStatus is a snapshot of a job state. It is returned by an async binding call as well as
+// by the job status endpoint.
+type JobStatus struct {
+	ID       string
+	Binding  string
+	State    string
+	Progress float64
+	Error    string `json:",omitempty"`
+	Created  time.Time
+	Started  time.Time
+	Finished time.Time
+}
+
+// jobQueue is a bounded worker pool that processes asynchronous binding invocations.
+type jobQueue struct {
+	jobs      map[string]*Job
+	queue     chan *Job
+	workers   int
+	retention time.Duration
+	started   bool
+	mutex     sync.Mutex
+}
+
+// newJobQueue creates a new job queue with the given amount of workers and maximum
+// count of pending jobs. Workers are started with the first submitted job.
+func newJobQueue(workers, size int) *jobQueue {
+	return &jobQueue{
+		jobs:      make(map[string]*Job),
+		queue:     make(chan *Job, size),
+		workers:   workers,
+		retention: DefaultJobRetention}
+}
+
+// generateJobID generates a random job identifier.
+func generateJobID() string {
+	b := make([]byte, jobIDLength)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Errorf("Could not generate job id: %s", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// newDetachedJob creates the job that is injected into synchronous calls. It has neither an id
+// nor a binding and is never canceled.
+func newDetachedJob() *Job {
+	return &Job{state: JobRunning, cancel: make(chan struct{})}
+}
+
+// detachedResponse is the response writer of a job. The response of the request that submitted
+// the job has already been sent, so anything written by the job is discarded.
+type detachedResponse struct {
+	header http.Header
+}
+
+func (r *detachedResponse) Header() http.Header         { return r.header }
+func (r *detachedResponse) Write(p []byte) (int, error) { return len(p), nil }
+func (r *detachedResponse) WriteHeader(status int)      {}
+
+// snapshotInjections copies the request specific injections for a job since the request is
+// answered before the job runs. The job gets a copy of the HTTP context with a detached
+// response and a copy of the session. Changes of the session are not sent to the client.
+func snapshotInjections(inj Injections) Injections {
+	ret := MergeInjections(inj)
+	if hc, ok := inj[typeOfHTTPContext].(*HTTPContext); ok && hc != nil {
+		c := *hc
+		if hc.Request != nil {
+			c.Request = hc.Request.Clone(context.Background())
+			c.Request.Body = http.NoBody
+		}
+		c.Response = &detachedResponse{header: make(http.Header)}
+		ret[typeOfHTTPContext] = &c
+	}
+	if s, ok := inj[typeOfSession].(*Session); ok && s != nil {
+		c := *s
+		c.Properties = copyProperties(s.Properties)
+		ret[typeOfSession] = &c
+	}
+	return ret
+}
+
+// ID returns the identifier of the job.
+func (j *Job) ID() string { return j.id }
+
+// SetProgress updates the progress of the job. The value is supposed to be between 0 and 1.
+func (j *Job) SetProgress(p float64) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.progress = p
+}
+
+// Canceled returns a channel that is closed once the job has been canceled.
+func (j *Job) Canceled() <-chan struct{} {
+	return j.cancel
+}
+
+// IsCanceled returns true if the job has been canceled.
+func (j *Job) IsCanceled() bool {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.canceled
+}
+
+// Status returns a snapshot of the current job state.
+func (j *Job) Status() JobStatus {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	var name string
+	if j.binding.bindingInterface != nil {
+		name = j.binding.Name()
+	}
+	return JobStatus{
+		ID:       j.id,
+		Binding:  name,
+		State:    j.state,
+		Progress: j.progress,
+		Error:    j.err,
+		Created:  j.created,
+		Started:  j.started,
+		Finished: j.finished}
+}
+
+// Result returns the result of a finished job. An error is returned if the job
+// is not yet finished, failed or has been canceled.
+func (j *Job) Result() (interface{}, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	switch j.state {
+	case JobDone:
+		return j.result, nil
+	case JobFailed:
+		return nil, fmt.Errorf("Job %s failed: %s", j.id, j.err)
+	default:
+		return nil, fmt.Errorf("Job %s is %s.", j.id, j.state)
+	}
+}
+
+// Cancel requests the cancellation of the job. Pending jobs will not be executed at all,
+// running jobs are notified via the Canceled channel.
+func (j *Job) Cancel() {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.canceled || j.state == JobDone || j.state == JobFailed {
+		return
+	}
+	j.canceled = true
+	close(j.cancel)
+	if j.state == JobPending {
+		j.state = JobCanceled
+		j.finished = time.Now()
+	}
+}
+
+// run executes the job within a worker.
+func (j *Job) run() {
+	j.mutex.Lock()
+	if j.canceled {
+		j.mutex.Unlock()
+		return
+	}
+	j.state = JobRunning
+	j.started = time.Now()
+	j.mutex.Unlock()
+
+	var ret interface{}
+	var err string
+	func() {
+		defer func() {
+			if re := recover(); re != nil {
+				err = fmt.Sprintf("%s", re)
+				log.Printf("Job %s of binding %s failed: %s", j.id, j.binding.Name(), err)
+			}
+		}()
+		ret = j.binding.invoke(j.inj, j.args)
+	}()
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.finished = time.Now()
+	switch {
+	case j.canceled:
+		j.state = JobCanceled
+	case len(err) > 0:
+		j.state = JobFailed
+		j.err = err
+	default:
+		j.state = JobDone
+		j.progress = 1
+		j.result = ret
+	}
+	j.inj = nil
+	j.args = nil
+}
+
+// start launches the workers of the job queue.
+func (q *jobQueue) start() {
+	for i := 0; i < q.workers; i++ {
+		go func() {
+			for j := range q.queue {
+				j.run()
+			}
+		}()
+	}
+	q.started = true
+}
+
+// purge removes finished jobs that exceeded the retention time.
+func (q *jobQueue) purge() {
+	deadline := time.Now().Add(-q.retention)
+	for id, j := range q.jobs {
+		j.mutex.Lock()
+		expired := !j.finished.IsZero() && j.finished.Before(deadline)
+		j.mutex.Unlock()
+		if expired {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// submit queues a new job for the given binding. It returns an error if the queue is full.
+func (q *jobQueue) submit(b Binding, inj Injections, args []interface{}) (*Job, error) {
+	j := &Job{
+		id:      generateJobID(),
+		state:   JobPending,
+		created: time.Now(),
+		cancel:  make(chan struct{}),
+		binding: b,
+		args:    args}
+	j.inj = MergeInjections(snapshotInjections(inj), NewI(j))
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if !q.started {
+		q.start()
+	}
+	q.purge()
+
+	select {
+	case q.queue <- j:
+		q.jobs[j.id] = j
+		return j, nil
+	default:
+		return nil, fmt.Errorf("Job queue is full (%d pending jobs).", cap(q.queue))
+	}
+}
+
+// job looks up a job by its id.
+func (q *jobQueue) job(id string) (j *Job, found bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	j, found = q.jobs[id]
+	return
+}
+
+// Async marks the binding as asynchronous. Calling the binding via HTTP returns the status
+// of a newly created job immediately while the actual invocation is processed by a bounded
+// worker pool. The job endpoints are exposed automatically in the internal interface.
+func (b Binding) Async() Binding {
+	bb := b.base()
+	bb.async = true
+	bb.container.exposeJobs()
+	bb.container.revision++
+	return b
+}
+
+// Async marks all given bindings as asynchronous. See Binding.Async for more information.
+func (bs Bindings) Async() Bindings {
+	for _, b := range bs {
+		b.Async()
+	}
+	return bs
+}
+
+// IsAsync returns true if the binding is processed asynchronously.
+func (b Binding) IsAsync() bool {
+	return b.base().async
+}
+
+// InvokeAsync applies the filter chain and submits the binding invocation to the job queue
+// of the container. If the call has been filtered, nil is returned.
+func (b Binding) InvokeAsync(ri Injections, args ...interface{}) (*Job, error) {
+	inj := b.mergeInjections(ri)
+	if !b.filter(inj) {
+		return nil, nil
+	}
+	return b.base().container.jobs.submit(b, inj, args)
+}
+
+// Job looks up an asynchronous job by its id.
+func (b *Container) Job(id string) (*Job, bool) {
+	return b.jobs.job(id)
+}
+
+// exposeJobs exposes the job status, result and cancellation endpoints in the internal interface.
+func (b *Container) exposeJobs() {
+	in := DefaultInternalInterfaceName
+	if _, found := b.Binding(in, "JobStatus"); found {
+		return
+	}
+
+	lookup := func(hc *HTTPContext, id string) *Job {
+		j, found := b.jobs.job(id)
+		if !found {
+			hc.Errorf(http.StatusNotFound, "Job %s not found.", id)
+		}
+		return j
+	}
+
+	b.ExposeFunction(func(hc *HTTPContext, id string) JobStatus {
+		return lookup(hc, id).Status()
+	}, in, "JobStatus")
+
+	b.ExposeFunction(func(hc *HTTPContext, id string) interface{} {
+		j := lookup(hc, id)
+		ret, err := j.Result()
+		if err != nil {
+			if j.Status().State == JobFailed {
+				hc.Errorf(http.StatusInternalServerError, "%s", err)
+			}
+			hc.Errorf(http.StatusConflict, "%s", err)
+		}
+		return ret
+	}, in, "JobResult")
+
+	b.ExposeFunction(func(hc *HTTPContext, id string) JobStatus {
+		j := lookup(hc, id)
+		j.Cancel()
+		return j.Status()
+	}, in, "JobCancel")
+}
+
+// processAsync is an internally used method that submits an asynchronous call and writes the
+// job status to the output.
+func (f Binding) processAsync(hc *HTTPContext, out io.Writer, injs Injections, args ...interface{}) (mime string) {
+	j, err := f.InvokeAsync(injs, args...)
+	if err != nil {
+		hc.Errorf(http.StatusServiceUnavailable, "%s", err)
+	}
+	if j == nil {
+		return
+	}
+	hc.ReturnStatus = http.StatusAccepted
+	return encodeResult(out, j.Status())
+}
@@ -0,0 +1,119 @@
+package gotojs
+
+import (
+	. "github.com/sebkl/gotojs/client"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAsyncInvocation(t *testing.T) {
+	co := NewContainer()
+	release := make(chan bool)
+	co.ExposeFunction(func(j *Job, a, b int) int {
+		j.SetProgress(0.5)
+		<-release
+		return a + b
+	}, "Async", "Add").Async()
+
+	b, _ := co.Binding("Async", "Add")
+	j, err := b.InvokeAsync(nil, 17, 4)
+	if err != nil {
+		t.Fatalf("Async invocation failed: %s", err)
+	}
+
+	if _, err := j.Result(); err == nil {
+		t.Errorf("Result of unfinished job must not be available.")
+	}
+
+	release <- true
+	for s := j.Status(); s.State != JobDone; s = j.Status() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if ret, err := j.Result(); err != nil || ret != 21 {
+		t.Errorf("Unexpected job result: %v/%d (%s)", ret, 21, err)
+	}
+}
+
+func TestAsyncCancel(t *testing.T) {
+	co := NewContainer(Properties{P_JOBWORKERS: "1"})
+	co.ExposeFunction(func(j *Job) string {
+		<-j.Canceled()
+		return "canceled"
+	}, "Async", "Wait").Async()
+
+	b, _ := co.Binding("Async", "Wait")
+	j1, _ := b.InvokeAsync(nil)
+	j2, _ := b.InvokeAsync(nil)
+
+	j2.Cancel()
+	if s := j2.Status().State; s != JobCanceled {
+		t.Errorf("Pending job was not canceled: %s", s)
+	}
+
+	j1.Cancel()
+	for s := j1.Status(); s.State != JobCanceled; s = j1.Status() {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAsyncClientAwait(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(j *Job, s string) string {
+		time.Sleep(50 * time.Millisecond)
+		return "Hello " + s
+	}, "Async", "Hello").Async()
+	co.ExposeFunction(func() string {
+		panic("Failed on purpose.")
+	}, "Async", "Fail").Async()
+
+	server := httptest.NewServer(co.Setup())
+	defer server.Close()
+
+	c := NewClient(server.URL + "/gotojs")
+	ret, err := c.InvokeAndAwait("Async", "Hello", "World")
+	if err != nil || ret != "Hello World" {
+		t.Errorf("Awaiting async call failed: %v (%s)", ret, err)
+	}
+
+	if _, err = c.InvokeAndAwait("Async", "Fail"); err == nil {
+		t.Errorf("Failed job must return an error.")
+	}
+
+	if _, err = c.Invoke(DefaultInternalInterfaceName, "JobStatus", "unknown"); err == nil {
+		t.Errorf("Unknown job must return an error.")
+	}
+}
+
+func TestJobInjections(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(j *Job) string {
+		select {
+		case <-j.Canceled():
+			return "canceled"
+		default:
+		}
+		if j.IsCanceled() || j.Status().State != JobRunning {
+			return "invalid"
+		}
+		return "done"
+	}, "Sync", "Check")
+
+	if ret := co.Invoke("Sync", "Check"); ret != "done" {
+		t.Errorf("Unexpected result of synchronous call: %v", ret)
+	}
+
+	hc := NewHTTPContext(httptest.NewRequest("POST", "/gotojs/Async/Session", nil), httptest.NewRecorder())
+	s := NewSession()
+	s.Set("user", "alice")
+	inj := snapshotInjections(NewI(hc, s))
+
+	s.Set("user", "bob")
+	if js := inj[typeOfSession].(*Session); js == s || js.Get("user") != "alice" {
+		t.Errorf("Session of the job has not been copied.")
+	}
+	if jhc := inj[typeOfHTTPContext].(*HTTPContext); jhc == hc || jhc.Response == hc.Response || jhc.Request.URL.Path != "/gotojs/Async/Session" {
+		t.Errorf("HTTP context of the job has not been copied.")
+	}
+}
@@ -0,0 +1,366 @@
+package gotojs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Supported JWT signing algorithms.
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Default values of the JWT handling.
+const (
+	DefaultJWTTTL     = time.Hour
+	DefaultRolesClaim = "roles"
+)
+
+var jwtEncoding = base64.RawURLEncoding
+
+// Claims represents the claims of a verified JWT. It will be injected whenever a binding declares
+// a parameter of type Claims. For calls without a token nil is injected.
+type Claims map[string]interface{}
+
+// String returns the string value of the given claim or an empty string.
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Subject returns the "sub" claim.
+func (c Claims) Subject() string { return c.String("sub") }
+
+// Time returns the given numeric date claim. The zero time is returned if the claim does not exist.
+func (c Claims) Time(name string) time.Time {
+	if f, ok := c[name].(float64); ok {
+		return time.Unix(int64(f), 0)
+	}
+	return time.Time{}
+}
+
+// Audience returns the "aud" claim which may either be a single string or a list of strings.
+func (c Claims) Audience() []string {
+	switch a := c["aud"].(type) {
+	case string:
+		return []string{a}
+	case []interface{}:
+		ret := make([]string, 0, len(a))
+		for _, v := range a {
+			if s, ok := v.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+		return ret
+	}
+	return nil
+}
+
+// Strings returns the given claim as a list of strings.
+func (c Claims) Strings(name string) (ret []string) {
+	switch v := c[name].(type) {
+	case string:
+		return strings.Split(v, ",")
+	case []interface{}:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				ret = append(ret, s)
+			}
+		}
+	}
+	return
+}
+
+// JWTKey is a key used to sign and verify tokens. HS256 keys use Secret. RS256 and ES256
+// keys use PrivateKey for signing and PublicKey for verification. If only the PublicKey is set,
+// the key can only be used for verification.
+type JWTKey struct {
+	ID         string
+	Algorithm  string
+	Secret     []byte
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// publicKey returns the key used for verification.
+func (k *JWTKey) publicKey() crypto.PublicKey {
+	if k.PublicKey == nil && k.PrivateKey != nil {
+		return k.PrivateKey.Public()
+	}
+	return k.PublicKey
+}
+
+// sign creates the signature of the given input.
+func (k *JWTKey) sign(input []byte) ([]byte, error) {
+	sum := sha256.Sum256(input)
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		if pk, ok := k.PrivateKey.(*rsa.PrivateKey); ok {
+			return rsa.SignPKCS1v15(rand.Reader, pk, crypto.SHA256, sum[:])
+		}
+	case ES256:
+		if pk, ok := k.PrivateKey.(*ecdsa.PrivateKey); ok {
+			r, s, err := ecdsa.Sign(rand.Reader, pk, sum[:])
+			if err != nil {
+				return nil, err
+			}
+			sig := make([]byte, 64)
+			r.FillBytes(sig[:32])
+			s.FillBytes(sig[32:])
+			return sig, nil
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported algorithm \"%s\".", k.Algorithm)
+	}
+	return nil, fmt.Errorf("Key \"%s\" cannot be used to sign %s tokens.", k.ID, k.Algorithm)
+}
+
+// verify checks the signature of the given input.
+func (k *JWTKey) verify(input, sig []byte) bool {
+	sum := sha256.Sum256(input)
+	switch k.Algorithm {
+	case HS256:
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(input)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case RS256:
+		if pk, ok := k.publicKey().(*rsa.PublicKey); ok {
+			return rsa.VerifyPKCS1v15(pk, crypto.SHA256, sum[:], sig) == nil
+		}
+	case ES256:
+		if pk, ok := k.publicKey().(*ecdsa.PublicKey); ok && len(sig) == 64 {
+			r := new(big.Int).SetBytes(sig[:32])
+			s := new(big.Int).SetBytes(sig[32:])
+			return ecdsa.Verify(pk, sum[:], r, s)
+		}
+	}
+	return false
+}
+
+// jwtHeader is the JOSE header of a token.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// JWT issues and verifies JSON web tokens. It implements the Authenticator interface and
+// accepts tokens as bearer token of the authorization header.
+type JWT struct {
+	// Issuer is set as "iss" claim and verified if not empty.
+	Issuer string
+
+	// Audience is set as "aud" claim and verified if not empty.
+	Audience string
+
+	// TTL is the lifetime of issued tokens. DefaultJWTTTL if 0.
+	TTL time.Duration
+
+	// Leeway is the accepted clock skew when verifying expiry and not before claims.
+	Leeway time.Duration
+
+	// RolesClaim is the claim that contains the roles of the principal. DefaultRolesClaim if empty.
+	RolesClaim string
+
+	// AllowNoExpiry accepts tokens without "exp" claim. These tokens never expire, so they are
+	// rejected by default.
+	AllowNoExpiry bool
+
+	keys    map[string]*JWTKey
+	signing *JWTKey
+	mutex   sync.RWMutex
+}
+
+// NewJWT creates a new JWT issuer and verifier for the given issuer and audience.
+func NewJWT(issuer, audience string) *JWT {
+	return &JWT{
+		Issuer:   issuer,
+		Audience: audience,
+		keys:     make(map[string]*JWTKey)}
+}
+
+// AddKey adds a key. The key added most recently is used to sign new tokens while
+// all keys are accepted for verification. This allows the rotation of keys by their kid.
+// Keys without private part are only used for verification.
+func (j *JWT) AddKey(k *JWTKey) *JWT {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.keys[k.ID] = k
+	if k.Algorithm == HS256 || k.PrivateKey != nil {
+		j.signing = k
+	}
+	return j
+}
+
+// RemoveKey removes a key. Tokens signed by this key are not accepted anymore.
+func (j *JWT) RemoveKey(kid string) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if j.signing != nil && j.signing.ID == kid {
+		j.signing = nil
+	}
+	delete(j.keys, kid)
+}
+
+// Sign creates a signed token of the given claims.
+func (j *JWT) Sign(c Claims) (string, error) {
+	j.mutex.RLock()
+	k := j.signing
+	j.mutex.RUnlock()
+	if k == nil {
+		return "", fmt.Errorf("No signing key available.")
+	}
+
+	h, err := json.Marshal(jwtHeader{Alg: k.Algorithm, Typ: "JWT", Kid: k.ID})
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	input := jwtEncoding.EncodeToString(h) + "." + jwtEncoding.EncodeToString(p)
+	sig, err := k.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + jwtEncoding.EncodeToString(sig), nil
+}
+
+// rolesClaim returns the name of the roles claim.
+func (j *JWT) rolesClaim() string {
+	if len(j.RolesClaim) == 0 {
+		return DefaultRolesClaim
+	}
+	return j.RolesClaim
+}
+
+// Issue creates a signed token for the given principal. Subject, roles, issuer, audience,
+// issue and expiry time are set in addition to the claims of the principal.
+func (j *JWT) Issue(p *Principal) (string, error) {
+	ttl := j.TTL
+	if ttl == 0 {
+		ttl = DefaultJWTTTL
+	}
+
+	now := time.Now()
+	c := make(Claims)
+	for k, v := range p.Claims {
+		c[k] = v
+	}
+	c["sub"] = p.Name
+	c["iat"] = now.Unix()
+	c["exp"] = now.Add(ttl).Unix()
+	if len(p.Roles) > 0 {
+		c[j.rolesClaim()] = p.Roles
+	}
+	if len(j.Issuer) > 0 {
+		c["iss"] = j.Issuer
+	}
+	if len(j.Audience) > 0 {
+		c["aud"] = j.Audience
+	}
+	return j.Sign(c)
+}
+
+// Verify checks the signature, expiry, issuer and audience of the given token and returns its claims.
+// Tokens without expiry are rejected unless AllowNoExpiry is set.
+func (j *JWT) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Malformed token.")
+	}
+
+	var h jwtHeader
+	if err := jwtDecode(parts[0], &h); err != nil {
+		return nil, fmt.Errorf("Invalid token header: %s", err)
+	}
+
+	j.mutex.RLock()
+	k, found := j.keys[h.Kid]
+	j.mutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("Unknown key \"%s\".", h.Kid)
+	}
+
+	// The algorithm is bound to the key. This prevents algorithm confusion.
+	if h.Alg != k.Algorithm {
+		return nil, fmt.Errorf("Algorithm \"%s\" does not match key \"%s\".", h.Alg, h.Kid)
+	}
+
+	sig, err := jwtEncoding.DecodeString(parts[2])
+	if err != nil || !k.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, fmt.Errorf("Invalid token signature.")
+	}
+
+	var c Claims
+	if err := jwtDecode(parts[1], &c); err != nil {
+		return nil, fmt.Errorf("Invalid token claims: %s", err)
+	}
+
+	now := time.Now()
+	exp := c.Time("exp")
+	if exp.IsZero() && !j.AllowNoExpiry {
+		return nil, fmt.Errorf("Token does not expire.")
+	}
+	if !exp.IsZero() && now.After(exp.Add(j.Leeway)) {
+		return nil, fmt.Errorf("Token expired at %s.", exp)
+	}
+	if nbf := c.Time("nbf"); !nbf.IsZero() && now.Before(nbf.Add(-j.Leeway)) {
+		return nil, fmt.Errorf("Token not valid before %s.", nbf)
+	}
+	if len(j.Issuer) > 0 && c.String("iss") != j.Issuer {
+		return nil, fmt.Errorf("Invalid token issuer \"%s\".", c.String("iss"))
+	}
+	if len(j.Audience) > 0 && !ContainsS(c.Audience(), j.Audience) {
+		return nil, fmt.Errorf("Token is not issued for audience \"%s\".", j.Audience)
+	}
+	return c, nil
+}
+
+// jwtDecode decodes a base64url encoded JSON segment of a token.
+func jwtDecode(seg string, v interface{}) error {
+	raw, err := jwtEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// Authenticate implements the Authenticator interface.
+func (j *JWT) Authenticate(hc *HTTPContext) (*Principal, error) {
+	token, ok := bearerToken(hc.Request)
+	if !ok {
+		return nil, nil
+	}
+
+	c, err := j.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	return &Principal{
+		Name:   c.Subject(),
+		Roles:  c.Strings(j.rolesClaim()),
+		Claims: c}, nil
+}
+
+// Challenge implements the Challenger interface.
+func (j *JWT) Challenge() string { return "Bearer" }
@@ -0,0 +1,115 @@
+package gotojs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWTAlgorithms(t *testing.T) {
+	rk, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ek, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	keys := []*JWTKey{
+		{ID: "h", Algorithm: HS256, Secret: []byte("secret")},
+		{ID: "r", Algorithm: RS256, PrivateKey: rk},
+		{ID: "e", Algorithm: ES256, PrivateKey: ek}}
+
+	for _, k := range keys {
+		j := NewJWT("gotojs", "app").AddKey(k)
+		token, err := j.Issue(&Principal{Name: "alice", Roles: []string{"admin"}})
+		if err != nil {
+			t.Fatalf("Could not issue %s token: %s", k.Algorithm, err)
+		}
+
+		c, err := j.Verify(token)
+		if err != nil || c.Subject() != "alice" || !ContainsS(c.Strings(DefaultRolesClaim), "admin") {
+			t.Errorf("Could not verify %s token: %s %v", k.Algorithm, err, c)
+		}
+
+		if _, err := j.Verify(token[:len(token)-4] + "AAAA"); err == nil {
+			t.Errorf("Tampered %s token has been accepted.", k.Algorithm)
+		}
+	}
+
+	// Verification by public key only.
+	v := NewJWT("gotojs", "app").AddKey(&JWTKey{ID: "r", Algorithm: RS256, PublicKey: &rk.PublicKey})
+	token, _ := NewJWT("gotojs", "app").AddKey(keys[1]).Issue(&Principal{Name: "bob"})
+	if _, err := v.Verify(token); err != nil {
+		t.Errorf("Token could not be verified by the public key: %s", err)
+	}
+}
+
+func TestJWTValidation(t *testing.T) {
+	j := NewJWT("gotojs", "app").AddKey(&JWTKey{ID: "1", Algorithm: HS256, Secret: []byte("one")})
+
+	j.TTL = -time.Minute
+	expired, _ := j.Issue(&Principal{Name: "alice"})
+	j.TTL = 0
+	if _, err := j.Verify(expired); err == nil {
+		t.Errorf("Expired token has been accepted.")
+	}
+
+	forever, _ := j.Sign(Claims{"sub": "alice", "iss": "gotojs", "aud": "app"})
+	if _, err := j.Verify(forever); err == nil {
+		t.Errorf("Token without expiry has been accepted.")
+	}
+	j.AllowNoExpiry = true
+	if _, err := j.Verify(forever); err != nil {
+		t.Errorf("Token without expiry has been rejected: %s", err)
+	}
+	j.AllowNoExpiry = false
+
+	other, _ := NewJWT("gotojs", "other").AddKey(&JWTKey{ID: "1", Algorithm: HS256, Secret: []byte("one")}).Issue(&Principal{Name: "alice"})
+	if _, err := j.Verify(other); err == nil {
+		t.Errorf("Token of a different audience has been accepted.")
+	}
+
+	// Rotation: tokens of the old key remain valid until the key is removed.
+	old, _ := j.Issue(&Principal{Name: "alice"})
+	j.AddKey(&JWTKey{ID: "2", Algorithm: HS256, Secret: []byte("two")})
+	current, _ := j.Issue(&Principal{Name: "alice"})
+
+	if _, err := j.Verify(old); err != nil {
+		t.Errorf("Token of the previous key has been rejected: %s", err)
+	}
+
+	j.RemoveKey("1")
+	if _, err := j.Verify(old); err == nil {
+		t.Errorf("Token of a removed key has been accepted.")
+	}
+	if _, err := j.Verify(current); err != nil {
+		t.Errorf("Token of the current key has been rejected: %s", err)
+	}
+}
+
+func TestJWTLogin(t *testing.T) {
+	j := NewJWT("gotojs", "app").AddKey(&JWTKey{ID: "1", Algorithm: HS256, Secret: []byte("secret")})
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.Authenticate(j)
+	co.ExposeFunction(func(user string) string {
+		token, _ := j.Issue(&Principal{Name: user, Roles: []string{"user"}})
+		return token
+	}, "Auth", "Login")
+	co.ExposeFunction(func(c Claims) string { return c.Subject() }, "Auth", "Me").Require("user")
+	h := co.Setup()
+
+	r := record(h, httptest.NewRequest("GET", "/gotojs/Auth/Login/alice", nil))
+	token := strings.Trim(r.Body.String(), "\"")
+
+	req := httptest.NewRequest("GET", "/gotojs/Auth/Me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if r := record(h, req); r.Body.String() != "\"alice\"" {
+		t.Errorf("Claims have not been injected: %s", r.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/gotojs/Auth/Me", nil)
+	req.Header.Set("Authorization", "Bearer "+token+"x")
+	if r := record(h, req); r.Code != 401 {
+		t.Errorf("Invalid token has not been rejected: %d/%d", r.Code, 401)
+	}
+}
@@ -0,0 +1,209 @@
+package gotojs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Module is a reusable feature package like authentication or health checks. It is installed
+// into a container where it exposes its bindings and adds filters, injections etc.
+type Module interface {
+	// Name returns the unique name of the module.
+	Name() string
+
+	// Install sets up the module with the given configuration.
+	Install(c *Container, config Properties) error
+}
+
+// ModuleDependent is implemented by modules that require other modules. The required modules
+// must be installed first.
+type ModuleDependent interface {
+	Requires() []string
+}
+
+// ModuleLifecycle is implemented by modules that need to be started and stopped along with the
+// container.
+type ModuleLifecycle interface {
+	Start(c *Container) error
+	Stop(c *Container) error
+}
+
+// Install installs the module with the given configuration. Modules are started in the order they
+// have been installed and stopped in reverse order. If the modules have already been started, the
+// module is started immediately. A module that fails to install or start is not added to the
+// container and the bindings, routes and container filters it registered are removed again.
+func (b *Container) Install(m Module, config ...Properties) error {
+	name := m.Name()
+	if _, found := b.Module(name); found {
+		return fmt.Errorf("Module \"%s\" already installed.", name)
+	}
+
+	if d, ok := m.(ModuleDependent); ok {
+		var missing []string
+		for _, r := range d.Requires() {
+			if _, found := b.Module(r); !found {
+				missing = append(missing, r)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("Module \"%s\" requires missing modules: %s", name, strings.Join(missing, ", "))
+		}
+	}
+
+	p := make(Properties)
+	for _, c := range config {
+		for k, v := range c {
+			p[k] = v
+		}
+	}
+
+	prev := b.registrations()
+	if err := m.Install(b, p); err != nil {
+		b.restore(prev)
+		return fmt.Errorf("Could not install module \"%s\": %s", name, err)
+	}
+
+	if b.modulesStarted {
+		if l, ok := m.(ModuleLifecycle); ok {
+			if err := l.Start(b); err != nil {
+				b.restore(prev)
+				return fmt.Errorf("Could not start module \"%s\": %s", name, err)
+			}
+		}
+	}
+	b.modules = append(b.modules, m)
+	b.revision++
+	return nil
+}
+
+// registrations is a snapshot of the bindings, routes and container filters of a container.
+type registrations struct {
+	bindings         bindingContainer
+	routes           []*Route
+	filters          []Filter
+	interfaceFilters map[string][]Filter
+}
+
+// registrations takes a snapshot of the bindings, routes and container filters.
+func (b *Container) registrations() *registrations {
+	r := &registrations{
+		bindings:         make(bindingContainer),
+		routes:           b.routes,
+		filters:          b.filters,
+		interfaceFilters: make(map[string][]Filter)}
+	for in, i := range b.bindingContainer {
+		r.bindings[in] = make(Interface)
+		for mn, bi := range i {
+			r.bindings[in][mn] = bi
+		}
+	}
+	for in, fs := range b.interfaceFilters {
+		r.interfaceFilters[in] = fs
+	}
+	return r
+}
+
+// restore resets the bindings, routes and container filters to the given snapshot.
+func (b *Container) restore(r *registrations) {
+	b.bindingContainer = r.bindings
+	b.routes = r.routes
+	b.filters = r.filters
+	b.interfaceFilters = r.interfaceFilters
+	b.revision++
+}
+
+// Module looks up an installed module by its name.
+func (b *Container) Module(name string) (Module, bool) {
+	for _, m := range b.modules {
+		if m.Name() == name {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// Modules returns the names of the installed modules in the order of their installation.
+func (b *Container) Modules() (ret []string) {
+	for _, m := range b.modules {
+		ret = append(ret, m.Name())
+	}
+	return
+}
+
+// StartModules starts the installed modules followed by the modules of the mounted containers.
+// It is called by Start and needs to be called explicitly if the container is used as handler.
+// If a module fails to start, the modules started before are stopped again.
+func (b *Container) StartModules() error {
+	if b.modulesStarted {
+		return nil
+	}
+
+	for i, m := range b.modules {
+		l, ok := m.(ModuleLifecycle)
+		if !ok {
+			continue
+		}
+		if err := l.Start(b); err != nil {
+			b.stopModules(i)
+			return fmt.Errorf("Could not start module \"%s\": %s", m.Name(), err)
+		}
+	}
+	b.modulesStarted = true
+
+	for _, m := range b.mounts {
+		if err := m.sub.StartModules(); err != nil {
+			b.stopAllModules()
+			return err
+		}
+	}
+	return nil
+}
+
+// stopModules stops the first n modules in reverse order.
+func (b *Container) stopModules(n int) (err error) {
+	for i := n - 1; i >= 0; i-- {
+		m := b.modules[i]
+		if l, ok := m.(ModuleLifecycle); ok {
+			if e := l.Stop(b); e != nil {
+				log.Printf("Could not stop module \"%s\": %s", m.Name(), e)
+				if err == nil {
+					err = fmt.Errorf("Could not stop module \"%s\": %s", m.Name(), e)
+				}
+			}
+		}
+	}
+	return
+}
+
+// stopAllModules stops the modules of the mounted containers in reverse order followed by the
+// started modules of this container.
+func (b *Container) stopAllModules() (err error) {
+	for i := len(b.mounts) - 1; i >= 0; i-- {
+		if e := b.mounts[i].sub.stopAllModules(); err == nil {
+			err = e
+		}
+	}
+
+	if b.modulesStarted {
+		b.modulesStarted = false
+		if e := b.stopModules(len(b.modules)); err == nil {
+			err = e
+		}
+	}
+	return
+}
+
+// Stop shuts down the http server started by Start, waiting for active requests to finish, and
+// stops the modules of the mounted containers and the installed modules in reverse order.
+func (b *Container) Stop() (err error) {
+	if b.httpd != nil {
+		err = b.httpd.Shutdown(context.Background())
+	}
+
+	if e := b.stopAllModules(); err == nil {
+		err = e
+	}
+	return
+}
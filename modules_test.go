@@ -0,0 +1,123 @@
+package gotojs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testModule struct {
+	name     string
+	requires []string
+	failures string
+	log      *[]string
+}
+
+func (m *testModule) Name() string       { return m.name }
+func (m *testModule) Requires() []string { return m.requires }
+
+func (m *testModule) Install(c *Container, config Properties) error {
+	c.ExposeFunction(func() string { return config["greeting"] }, m.name, "Greet")
+	c.IfInterface(m.name, func(b Binding, inj Injections) bool { return true })
+	*m.log = append(*m.log, "install "+m.name)
+	return nil
+}
+
+func (m *testModule) Start(c *Container) error {
+	if strings.Contains(m.failures, "start") {
+		return errors.New("failed")
+	}
+	*m.log = append(*m.log, "start "+m.name)
+	return nil
+}
+
+func (m *testModule) Stop(c *Container) error {
+	*m.log = append(*m.log, "stop "+m.name)
+	return nil
+}
+
+func TestModules(t *testing.T) {
+	co := NewContainer()
+	var log []string
+
+	audit := &testModule{name: "audit", requires: []string{"auth"}, log: &log}
+	if err := co.Install(audit); err == nil {
+		t.Errorf("Module with missing dependency has been installed.")
+	}
+
+	if err := co.Install(&testModule{name: "auth", log: &log}, Properties{"greeting": "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := co.Install(audit); err != nil {
+		t.Fatal(err)
+	}
+	if err := co.Install(&testModule{name: "auth", log: &log}); err == nil {
+		t.Errorf("Module has been installed twice.")
+	}
+
+	if res := co.Invoke("auth", "Greet"); res != "hello" {
+		t.Errorf("Module configuration has not been passed: %v", res)
+	}
+
+	if err := co.StartModules(); err != nil {
+		t.Fatal(err)
+	}
+	co.Install(&testModule{name: "health", log: &log})
+	if err := co.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "install auth,install audit,start auth,start audit,install health,start health,stop health,stop audit,stop auth"
+	if s := strings.Join(log, ","); s != expected {
+		t.Errorf("Unexpected module lifecycle: %s", s)
+	}
+
+	if m := strings.Join(co.Modules(), ","); m != "auth,audit,health" {
+		t.Errorf("Unexpected modules: %s", m)
+	}
+}
+
+func TestModuleStartFailure(t *testing.T) {
+	co := NewContainer()
+	var log []string
+	co.Install(&testModule{name: "a", log: &log})
+	co.Install(&testModule{name: "b", failures: "start", log: &log})
+
+	if err := co.StartModules(); err == nil {
+		t.Errorf("Failing module start has not been reported.")
+	}
+	if s := strings.Join(log, ","); s != "install a,install b,start a,stop a" {
+		t.Errorf("Started modules have not been stopped: %s", s)
+	}
+}
+
+func TestModuleLateStartFailure(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	var log []string
+	co.Install(&testModule{name: "a", log: &log})
+	if err := co.StartModules(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := co.Install(&testModule{name: "b", failures: "start", log: &log}); err == nil {
+		t.Errorf("Failing module start has not been reported.")
+	}
+	if _, found := co.Module("b"); found {
+		t.Errorf("Module failed to start has been added.")
+	}
+	if _, found := co.Binding("b", "Greet"); found || len(co.interfaceFilters["b"]) > 0 {
+		t.Errorf("Registrations of the module failed to start have been kept.")
+	}
+	if _, found := co.Binding("a", "Greet"); !found {
+		t.Errorf("Registrations of the started module have been removed.")
+	}
+	co.Stop()
+
+	// The server cannot be started on an invalid address.
+	if err := co.Start("invalid:address:0"); err == nil {
+		t.Errorf("Server has been started on an invalid address.")
+	}
+	if s := strings.Join(log, ","); s != "install a,start a,install b,stop a,start a,stop a" {
+		t.Errorf("Unexpected module lifecycle: %s", s)
+	}
+}
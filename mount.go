@@ -0,0 +1,122 @@
+package gotojs
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// mountPrefix validates the prefix of a mounted container. It is used as JS identifier.
+var mountPrefix = regexp.MustCompile(`^[A-Za-z$][A-Za-z0-9$]*$`)
+
+// mount is a container mounted into another one.
+type mount struct {
+	prefix string
+	sub    *Container
+}
+
+// Mount delegates the calls of the sub-context prefix to the given container. Its bindings are
+// called like "/gotojs/prefix/Interface/Method" and published by the engine of this container
+// as "GOTOJS.prefix.Interface.Method". The sub container keeps applying its own filters,
+// injections, converters and session keys. It gets its own namespace and session cookie in order
+// to avoid collisions. Its routes and handlers are served as well unless this container handles
+// the same paths. Its modules are started and stopped along with the modules of this container.
+func (f *Container) Mount(prefix string, sub *Container) {
+	if !mountPrefix.MatchString(prefix) {
+		panic(fmt.Errorf("Invalid mount prefix \"%s\".", prefix))
+	}
+	if sub == f {
+		panic(fmt.Errorf("Container cannot be mounted into itself."))
+	}
+	for _, m := range f.mounts {
+		if m.prefix == prefix {
+			panic(fmt.Errorf("Prefix \"%s\" is already mounted.", prefix))
+		}
+	}
+	if ContainsS(f.InterfaceNames(), prefix) {
+		panic(fmt.Errorf("Mount prefix \"%s\" collides with an interface.", prefix))
+	}
+
+	m := &mount{prefix: prefix, sub: sub}
+	f.attach(m)
+	sub.flags &^= F_LOAD_LIBRARIES // Libraries are provided by this container.
+	if sub.cookie.Name == f.cookie.Name {
+		c := *sub.cookie // The policy may be shared with this container.
+		c.Name += "_" + prefix
+		sub.cookie = &c
+	}
+
+	if f.modulesStarted {
+		if err := sub.StartModules(); err != nil {
+			panic(err)
+		}
+	}
+	f.mounts = append(f.mounts, m)
+	f.revision++
+}
+
+// attach derives the context, namespace and external url of the mounted container from this
+// container. It is called again whenever the context of this container changes.
+func (f *Container) attach(m *mount) {
+	m.sub.Context(f.context + m.prefix + "/")
+	m.sub.namespace = f.namespace + "_" + m.prefix
+	if f.extUrl != nil {
+		u := *f.extUrl
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + m.prefix
+		m.sub.extUrl = &u
+	}
+	m.sub.revision++
+}
+
+// contextOf returns the mounted container whose gotojs context contains the path.
+func (f *Container) contextOf(path string) *Container {
+	for _, m := range f.mounts {
+		if strings.HasPrefix(path, m.sub.context) {
+			if sub := m.sub.contextOf(path); sub != nil {
+				return sub
+			}
+			return m.sub
+		}
+	}
+	return nil
+}
+
+// checkMountCollision panics if the interface name collides with a mount prefix.
+func (f *Container) checkMountCollision(in string) {
+	for _, m := range f.mounts {
+		if m.prefix == in {
+			panic(fmt.Errorf("Interface \"%s\" collides with a mounted container.", in))
+		}
+	}
+}
+
+// Mounts returns the prefixes of the mounted containers.
+func (f *Container) Mounts() (ret []string) {
+	for _, m := range f.mounts {
+		ret = append(ret, m.prefix)
+	}
+	return
+}
+
+// buildMounts writes the engines of the mounted containers and attaches their namespaces to the
+// namespace of this container.
+func (f *Container) buildMounts(hc *HTTPContext, out io.Writer) {
+	for _, m := range f.mounts {
+		// Let the request look like a request of the sub container.
+		r := *hc.Request
+		u := *r.URL
+		u.Path = m.sub.context + strings.TrimPrefix(u.Path, f.context)
+		r.URL = &u
+
+		shc := *hc
+		shc.Request, shc.Container = &r, m.sub
+		m.sub.build(&shc, out)
+
+		s := shc.Session(m.sub.keys...)
+		m.sub.writeCSRF(out, &r, s)
+		s.Flush(hc.Response, m.sub.key)
+
+		fmt.Fprintf(out, "\n%s.%s = %s;\n", f.namespace, m.prefix, m.sub.namespace)
+	}
+}
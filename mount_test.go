@@ -0,0 +1,155 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type billingConfig struct {
+	currency string
+}
+
+func TestMount(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func() string { return "main" }, "Main", "Name")
+
+	sub := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	sub.SetupGlobalInjection(&billingConfig{currency: "EUR"})
+	sub.ExposeFunction(func(c *billingConfig, s *Session, v int) string {
+		s.Set("last", c.currency)
+		return c.currency
+	}, "Main", "Total")
+	sub.If(func(b Binding, inj Injections) bool {
+		if hc := inj[typeOfHTTPContext].(*HTTPContext); hc.Request != nil && hc.Request.Header.Get("x-deny") != "" {
+			return Reject(http.StatusForbidden, "Denied by billing.")
+		}
+		return true
+	})
+
+	co.Mount("billing", sub)
+	h := co.Setup()
+
+	call := func(path, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		if len(header) > 0 {
+			req.Header.Set(header[0], header[1])
+		}
+		return record(h, req)
+	}
+
+	if r := call("/gotojs/Main/Name", "[]"); r.Body.String() != `"main"` {
+		t.Errorf("Binding of the container could not be called: %s", r.Body.String())
+	}
+
+	r := call("/gotojs/billing/Main/Total", "[1]")
+	if r.Body.String() != `"EUR"` {
+		t.Errorf("Binding of the mounted container could not be called: %d %s", r.Code, r.Body.String())
+	}
+	if cs := r.Result().Cookies(); len(cs) != 1 || cs[0].Name != DefaultCookieName+"_billing" || SessionFromCookie(cs[0], sub.keys...).Get("last") != "EUR" {
+		t.Errorf("Mounted container did not use its own session: %v", cs)
+	}
+
+	if r := call("/gotojs/billing/Main/Total", "[1]", "x-deny", "1"); r.Code != http.StatusForbidden {
+		t.Errorf("Filter of the mounted container has not been applied: %d", r.Code)
+	}
+
+	e := call("/gotojs/", "").Body.String()
+	for _, s := range []string{"GOTOJS_billing.Main.Total", "GOTOJS.Main.Name", "GOTOJS.billing = GOTOJS_billing;", `"/gotojs/billing/"+i`} {
+		if !strings.Contains(e, s) {
+			t.Errorf("Engine does not contain \"%s\".", s)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Colliding mount has been accepted.")
+		}
+	}()
+	co.Mount("Main", NewContainer())
+}
+
+func TestMountSharedCookiePolicy(t *testing.T) {
+	p := NewCookiePolicy()
+	co, sub := NewContainer(), NewContainer()
+	co.SetCookiePolicy(p)
+	sub.SetCookiePolicy(p)
+	co.Mount("billing", sub)
+
+	if co.CookiePolicy().Name != DefaultCookieName || p.Name != DefaultCookieName {
+		t.Errorf("Session cookie of the container has been renamed: %s", co.CookiePolicy().Name)
+	}
+	if sub.CookiePolicy().Name != DefaultCookieName+"_billing" {
+		t.Errorf("Session cookie of the mounted container has not been renamed: %s", sub.CookiePolicy().Name)
+	}
+}
+
+func TestMountHandlers(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.HandleStatic("/shared", "main")
+
+	sub := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	b := sub.ExposeFunction(func(id string) string { return "invoice " + id }, "Invoices", "Get")[0]
+	sub.Route("GET", "/invoices/{id}", b)
+	sub.HandleStatic("/billing.html", "billing")
+	sub.HandleStatic("/shared", "sub")
+
+	co.Mount("billing", sub)
+	h := co.Setup("", "/api") // Changes the context after mounting.
+
+	get := func(path string) string {
+		return record(h, httptest.NewRequest("GET", path, nil)).Body.String()
+	}
+
+	for path, expected := range map[string]string{
+		"/api/billing/Invoices/Get/1": `"invoice 1"`,
+		"/invoices/2":                 `"invoice 2"`,
+		"/billing.html":               "billing",
+		"/shared":                     "main"} {
+		if body := get(path); body != expected {
+			t.Errorf("Unexpected response of \"%s\": %s", path, body)
+		}
+	}
+	if !strings.Contains(get("/api/"), `"/api/billing/"+i`) {
+		t.Errorf("Context of the mounted container has not been updated.")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Interface colliding with a mount has been exposed.")
+		}
+	}()
+	co.ExposeFunction(func() string { return "" }, "billing", "Total")
+}
+
+func TestMountModules(t *testing.T) {
+	var log []string
+	co := NewContainer()
+	co.Install(&testModule{name: "main", log: &log})
+	sub := NewContainer()
+	sub.Install(&testModule{name: "billing", log: &log})
+	co.Mount("billing", sub)
+
+	if err := co.StartModules(); err != nil {
+		t.Fatal(err)
+	}
+	if err := co.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if s := strings.Join(log, ","); s != "install main,install billing,start main,start billing,stop billing,stop main" {
+		t.Errorf("Modules of the mounted container have not been cascaded: %s", s)
+	}
+
+	log = nil
+	failing := NewContainer()
+	failing.Install(&testModule{name: "failing", failures: "start", log: &log})
+	co.Mount("failing", failing)
+	if err := co.StartModules(); err == nil {
+		t.Errorf("Failing module of a mounted container has not been reported.")
+	}
+	if s := strings.Join(log, ","); s != "install failing,start main,start billing,stop billing,stop main" {
+		t.Errorf("Started modules have not been stopped: %s", s)
+	}
+}
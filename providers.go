@@ -0,0 +1,232 @@
+package gotojs
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	typeOfError   = reflect.TypeOf((*error)(nil)).Elem()
+	typeOfCleanup = reflect.TypeOf(func() {})
+)
+
+// provider is a registered provider function.
+type provider struct {
+	fn   reflect.Value
+	out  reflect.Type
+	deps []reflect.Type
+}
+
+// Provide registers a provider function that creates injection objects of its first return type
+// for each call. Supported signatures are:
+//
+//	func(deps...) T
+//	func(deps...) (T, error)
+//	func(deps...) (T, func(), error)
+//
+// The parameters of the provider are resolved like the ones of a binding. They may be injected
+// objects such as *HTTPContext or the types of other providers, which are run first. A parameter
+// of a non-empty interface type receives the only injected or provided type implementing it.
+// Cyclic dependencies cause a panic right away, missing or ambiguous ones as soon as a binding
+// requiring the provider is exposed or the container is set up. Providers
+// are only run for calls of bindings declaring a parameter of type T, right before the call and
+// after the filter chain. Objects of type T passed along with the call take precedence. The
+// returned cleanup function is called once the call has finished.
+func (b *Container) Provide(fn interface{}) {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		panic(fmt.Errorf("Provider must be a function: %s", t))
+	}
+
+	switch {
+	case t.NumOut() == 1:
+	case t.NumOut() == 2 && t.Out(1) == typeOfError:
+	case t.NumOut() == 3 && t.Out(1) == typeOfCleanup && t.Out(2) == typeOfError:
+	default:
+		panic(fmt.Errorf("Invalid provider signature: %s", t))
+	}
+
+	p := &provider{fn: v, out: t.Out(0)}
+	for i := 0; i < t.NumIn(); i++ {
+		p.deps = append(p.deps, t.In(i))
+	}
+	if _, found := b.providers[p.out]; found {
+		panic(fmt.Errorf("Provider for type \"%s\" already registered.", p.out))
+	}
+
+	b.providers[p.out] = p
+	defer func() {
+		if re := recover(); re != nil {
+			delete(b.providers, p.out)
+			panic(re)
+		}
+	}()
+	b.checkProvider(p.out, b.globalInjections, false)
+	b.Bindings().declareInjection(p.out)
+}
+
+// addProviders declares the types of all providers as injections of the binding and checks
+// that the providers it requires can be run.
+func (b Binding) addProviders() {
+	bb := b.base()
+	for t := range bb.container.providers {
+		b.declareInjection(t)
+	}
+	bb.container.checkProviders(bb.injections, bb.singletons)
+}
+
+// checkProviders checks the providers of the given injected types against the given injections.
+func (b *Container) checkProviders(injections map[int]reflect.Type, inj Injections) {
+	for _, t := range injections {
+		b.checkProvider(t, inj, true)
+	}
+}
+
+// checkAllProviders checks the providers required by any of the bindings. Providers registered
+// after a binding has been exposed are only checked here.
+func (b *Container) checkAllProviders() {
+	for _, bi := range b.Bindings() {
+		bb := bi.base()
+		b.checkProviders(bb.injections, bb.singletons)
+	}
+}
+
+// checkProvider checks the dependencies of the provider of the given type against the given
+// injections and the other providers. Cyclic dependencies cause a panic. Missing and ambiguous
+// dependencies only do so if strict is set, as they may still be registered later on.
+func (b *Container) checkProvider(t reflect.Type, inj Injections, strict bool, path ...reflect.Type) {
+	if _, found := inj[t]; found {
+		return
+	}
+	p, found := b.providers[t]
+	if !found {
+		return
+	}
+	for _, pt := range path {
+		if pt == t {
+			panic(fmt.Errorf("Cyclic provider dependency: %s -> %s", path, t))
+		}
+	}
+
+	for _, d := range p.deps {
+		dt, err := b.dependency(d, inj)
+		if err != nil {
+			if strict {
+				panic(fmt.Errorf("Dependency \"%s\" of provider of \"%s\": %s", d, t, err))
+			}
+			continue
+		}
+		b.checkProvider(dt, inj, strict, append(path, t)...)
+	}
+}
+
+// dependency returns the type satisfying the given provider dependency. It is either the type
+// itself if it is injected or provided, or the only injected or provided type implementing the
+// non-empty interface type. The built-in injections are only used by their exact type.
+func (b *Container) dependency(d reflect.Type, inj Injections) (reflect.Type, error) {
+	if _, found := inj[d]; found {
+		return d, nil
+	}
+	if _, found := b.providers[d]; found {
+		return d, nil
+	}
+
+	var match []string
+	var ret reflect.Type
+	if d.Kind() == reflect.Interface && d.NumMethod() > 0 {
+		for t := range inj {
+			if !b.builtinInjections[t] && t.Implements(d) {
+				match, ret = append(match, t.String()), t
+			}
+		}
+		for t := range b.providers {
+			if _, found := inj[t]; !found && t.Implements(d) {
+				match, ret = append(match, t.String()), t
+			}
+		}
+	}
+
+	switch len(match) {
+	case 0:
+		return nil, fmt.Errorf("Injection not found.")
+	case 1:
+		return ret, nil
+	default:
+		sort.Strings(match)
+		return nil, fmt.Errorf("Ambiguous injection: %s", strings.Join(match, ", "))
+	}
+}
+
+// provide runs the providers of all injections the binding requires and adds the results to
+// the injections. It returns a function running the cleanup hooks in reverse order.
+func (b Binding) provide(inj Injections) func() {
+	var cleanups []func()
+	cleanup := func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cleanups[i]()
+		}
+	}
+
+	c := b.base().container
+	var resolve func(t reflect.Type, path []reflect.Type)
+	resolve = func(t reflect.Type, path []reflect.Type) {
+		if _, found := inj[t]; found {
+			return
+		}
+		p, found := c.providers[t]
+		if !found {
+			return // Reported by the call as missing injection.
+		}
+		for _, pt := range path {
+			if pt == t {
+				panic(fmt.Errorf("Cyclic provider dependency: %s -> %s", path, t))
+			}
+		}
+
+		in := make([]reflect.Value, len(p.deps))
+		for i, d := range p.deps {
+			dt, err := c.dependency(d, inj)
+			if err != nil {
+				panic(fmt.Errorf("Dependency \"%s\" of provider of \"%s\": %s", d, t, err))
+			}
+			resolve(dt, append(path, t))
+			v := inj[dt]
+			if v == nil {
+				in[i] = reflect.Zero(d)
+			} else {
+				in[i] = reflect.ValueOf(v)
+			}
+		}
+
+		out := p.fn.Call(in)
+		if n := len(out); n > 1 && !out[n-1].IsNil() {
+			panic(fmt.Errorf("Provider of \"%s\" failed: %s", t, out[n-1].Interface()))
+		}
+		if len(out) == 3 && !out[1].IsNil() {
+			cleanups = append(cleanups, out[1].Interface().(func()))
+		}
+		inj[t] = out[0].Interface()
+	}
+
+	defer func() {
+		if re := recover(); re != nil {
+			cleanup()
+			panic(re)
+		}
+	}()
+
+	for _, t := range b.base().injections {
+		resolve(t, nil)
+	}
+	return cleanup
+}
+
+// invoke runs the providers and calls the binding through its interceptors with the given
+// injections.
+func (b Binding) invoke(inj Injections, args []interface{}) interface{} {
+	defer b.provide(inj)()
+	return b.intercept(inj, args)
+}
@@ -0,0 +1,116 @@
+package gotojs
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type providedDB struct {
+	name   string
+	closed bool
+}
+
+type providedTx struct {
+	db *providedDB
+}
+
+func TestProvide(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	var log []string
+
+	co.ExposeFunction(func(tx *providedTx, v string) string { return tx.db.name + ":" + v }, "DB", "Query")
+	co.Provide(func(db *providedDB) (*providedTx, func(), error) {
+		log = append(log, "tx")
+		return &providedTx{db: db}, func() { log = append(log, "rollback") }, nil
+	})
+	co.Provide(func(hc *HTTPContext) (*providedDB, func(), error) {
+		if hc.Request.Header.Get("x-db") == "fail" {
+			return nil, nil, errors.New("no database")
+		}
+		log = append(log, "db")
+		db := &providedDB{name: hc.Request.Header.Get("x-db")}
+		return db, func() { db.closed = true; log = append(log, "close") }, nil
+	})
+	co.ExposeFunction(func(v string) string { return v }, "DB", "Echo")
+	h := co.Setup()
+
+	call := func(path, db string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`["x"]`))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		req.Header.Set("x-db", db)
+		return record(h, req)
+	}
+
+	if r := call("/gotojs/DB/Query", "main"); r.Body.String() != `"main:x"` {
+		t.Errorf("Provided injection has not been used: %s", r.Body.String())
+	}
+	if strings.Join(log, ",") != "db,tx,rollback,close" {
+		t.Errorf("Providers did not run in dependency order: %v", log)
+	}
+
+	log = nil
+	if r := call("/gotojs/DB/Echo", "main"); r.Body.String() != `"x"` || len(log) > 0 {
+		t.Errorf("Providers ran for a binding not requiring them: %v", log)
+	}
+
+	if r := call("/gotojs/DB/Query", "fail"); r.Code != http.StatusInternalServerError {
+		t.Errorf("Failing provider did not abort the call: %d", r.Code)
+	}
+
+	tx := &providedTx{db: &providedDB{name: "given"}}
+	if ret := co.InvokeI("DB", "Query", NewI(tx), "y"); ret != "given:y" {
+		t.Errorf("Given injection has been replaced by the provider: %v", ret)
+	}
+}
+
+type providedNamer interface {
+	Name() string
+}
+
+func (db *providedDB) Name() string { return db.name }
+
+type providedCache struct{}
+
+func TestProvideDependencies(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	panics := func(f func()) (ret bool) {
+		defer func() { ret = recover() != nil }()
+		f()
+		return
+	}
+
+	co.Provide(func(n providedNamer) *providedTx { return &providedTx{db: &providedDB{name: n.Name()}} })
+	co.Provide(func() *providedDB { return &providedDB{name: "main"} })
+	co.ExposeFunction(func(tx *providedTx) string { return tx.db.name }, "DB", "Name")
+	if ret := co.Invoke("DB", "Name"); ret != "main" {
+		t.Errorf("Interface dependency has not been resolved: %v", ret)
+	}
+
+	if !panics(func() { co.Provide(func(tx *providedTx) providedNamer { return tx.db }) }) {
+		t.Errorf("Cyclic provider dependency has not been rejected.")
+	}
+	if ret := co.Invoke("DB", "Name"); ret != "main" {
+		t.Errorf("Rejected provider changed the binding: %v", ret)
+	}
+
+	co.Provide(func(c *providedCache) *int { i := 1; return &i })
+	if !panics(func() { co.ExposeFunction(func(i *int) int { return *i }, "DB", "Count") }) {
+		t.Errorf("Binding requiring a provider with a missing dependency has been exposed.")
+	}
+
+	co.ExposeFunction(func(s *string) string { return *s }, "DB", "Label")
+	co.Provide(func(c *providedCache) *string { s := "label"; return &s })
+	if !panics(func() { co.Setup() }) {
+		t.Errorf("Provider with a missing dependency has been accepted for an exposed binding.")
+	}
+
+	co.SetupGlobalInjection(&providedCache{})
+	co.ExposeFunction(func(i *int) int { return *i }, "DB", "Count")
+	co.Setup()
+	if ret := co.Invoke("DB", "Count"); ret != 1 {
+		t.Errorf("Provider did not receive the global injection: %v", ret)
+	}
+}
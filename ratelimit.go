@@ -0,0 +1,229 @@
+package gotojs
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default values of the rate limiting.
+const (
+	DefaultHeaderAPIKey       = "x-gotojs-apikey"
+	DefaultRateLimitSweepTime = time.Minute
+)
+
+// RemoteIPKey is a KeyFunc that derives the key from the remote ip address of the caller.
+func RemoteIPKey(inj Injections) string {
+	hc, _ := inj[typeOfHTTPContext].(*HTTPContext)
+	if hc == nil || hc.Request == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(hc.Request.RemoteAddr); err == nil {
+		return host
+	}
+	return hc.Request.RemoteAddr
+}
+
+// APIKeyKey is a KeyFunc that derives the key from the validated api key of the call. Calls
+// without a valid api key are keyed by their remote ip address.
+func APIKeyKey(inj Injections) string {
+	if k, _ := inj[typeOfAPIKey].(*APIKey); k != nil {
+		return "apikey:" + k.Key
+	}
+	return "ip:" + RemoteIPKey(inj)
+}
+
+// SessionIDKey is a KeyFunc that derives the key from the ID of the session of the caller.
+// Callers that did not send a session cookie are assigned a session, but keyed by their remote
+// ip address, as they would get a fresh session for each call otherwise.
+func SessionIDKey(inj Injections) string {
+	s, _ := inj[typeOfSession].(*Session)
+	if s == nil {
+		return "ip:" + RemoteIPKey(inj)
+	}
+	id := s.ID()
+	if !s.sent {
+		return "ip:" + RemoteIPKey(inj)
+	}
+	return "session:" + id
+}
+
+// RateLimit declares a token bucket based rate limit. The bucket holds up to Burst tokens
+// and is refilled with Rate tokens per second. Each call takes one token.
+type RateLimit struct {
+	// Name identifies the limit for monitoring purposes. If empty, it is derived from the binding names.
+	Name string
+
+	// Rate is the amount of calls per second.
+	Rate float64
+
+	// Burst is the maximum amount of calls at once.
+	Burst int
+
+	// Key derives the bucket key from the injections. If nil, RemoteIPKey is used.
+	Key KeyFunc
+}
+
+// RateLimitStats contains the counters of a rate limit.
+type RateLimitStats struct {
+	Allowed  uint64
+	Rejected uint64
+}
+
+// RateLimitStore keeps the token buckets of the rate limits. It may be implemented by a shared
+// store in order to apply rate limits across multiple instances.
+type RateLimitStore interface {
+	// Take takes a token from the bucket identified by key. If the bucket is empty, false and
+	// the duration until the next token is available are returned.
+	Take(key string, rate float64, burst int) (bool, time.Duration)
+}
+
+// bucket is a single token bucket.
+type bucket struct {
+	tokens float64
+	rate   float64
+	burst  int
+	last   time.Time
+}
+
+// refill adds the tokens that accrued since the last access.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.burst), b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// MemoryRateLimitStore is an in-memory implementation of RateLimitStore.
+type MemoryRateLimitStore struct {
+	buckets map[string]*bucket
+	swept   time.Time
+	mutex   sync.Mutex
+}
+
+// NewMemoryRateLimitStore creates a new empty in-memory store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*bucket),
+		swept:   time.Now()}
+}
+
+// Take implements the RateLimitStore interface.
+func (s *MemoryRateLimitStore) Take(key string, rate float64, burst int) (bool, time.Duration) {
+	now := time.Now()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.swept) > DefaultRateLimitSweepTime {
+		s.sweep(now)
+	}
+
+	b, found := s.buckets[key]
+	if !found {
+		b = &bucket{tokens: float64(burst), last: now}
+		s.buckets[key] = b
+	}
+	b.rate, b.burst = rate, burst
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	if rate <= 0 {
+		return false, DefaultRateLimitSweepTime
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+// sweep removes buckets that are completely refilled anyway. The store must be locked.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	for k, b := range s.buckets {
+		if b.refill(now); b.tokens >= float64(b.burst) {
+			delete(s.buckets, k)
+		}
+	}
+	s.swept = now
+}
+
+// rateLimiter is a rate limit that is assigned to one or more bindings.
+type rateLimiter struct {
+	RateLimit
+	allowed  uint64
+	rejected uint64
+}
+
+// take takes a token for the call described by the given injections.
+func (l *rateLimiter) take(s RateLimitStore, inj Injections) (bool, time.Duration) {
+	kf := l.Key
+	if kf == nil {
+		kf = RemoteIPKey
+	}
+
+	ok, wait := s.Take(l.Name+"\x00"+kf(inj), l.Rate, l.Burst)
+	if ok {
+		atomic.AddUint64(&l.allowed, 1)
+	} else {
+		atomic.AddUint64(&l.rejected, 1)
+	}
+	return ok, wait
+}
+
+// RateLimit declares a rate limit for the binding.
+func (b Binding) RateLimit(l RateLimit) Binding {
+	return b.S().RateLimit(l)[0]
+}
+
+// RateLimit declares a rate limit that is shared by all given bindings. Calls exceeding the
+// limit are answered with status 429 and a Retry-After header.
+func (bs Bindings) RateLimit(l RateLimit) Bindings {
+	if len(l.Name) == 0 {
+		names := make([]string, len(bs))
+		for i, b := range bs {
+			names[i] = b.Name()
+		}
+		l.Name = strings.Join(names, ",")
+	}
+
+	rl := &rateLimiter{RateLimit: l}
+	for _, b := range bs {
+		bb := b.base()
+		bb.rateLimiters = append(bb.rateLimiters, rl)
+	}
+	return bs
+}
+
+// SetRateLimitStore replaces the store of the rate limit buckets. By default an in-memory
+// store is used.
+func (b *Container) SetRateLimitStore(s RateLimitStore) {
+	b.rateLimitStore = s
+}
+
+// RateLimitStats returns the counters of all declared rate limits keyed by their name.
+func (b *Container) RateLimitStats() map[string]RateLimitStats {
+	ret := make(map[string]RateLimitStats)
+	for _, bi := range b.Bindings() {
+		for _, rl := range bi.base().rateLimiters {
+			ret[rl.Name] = RateLimitStats{
+				Allowed:  atomic.LoadUint64(&rl.allowed),
+				Rejected: atomic.LoadUint64(&rl.rejected)}
+		}
+	}
+	return ret
+}
+
+// limit enforces the rate limits of the binding.
+func (b Binding) limit(hc *HTTPContext, injs Injections) {
+	bb := b.base()
+	for _, rl := range bb.rateLimiters {
+		if ok, wait := rl.take(bb.container.rateLimitStore, injs); !ok {
+			hc.Response.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(wait.Seconds()))))
+			hc.Errorf(http.StatusTooManyRequests, "Rate limit \"%s\" exceeded.", rl.Name)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	for i := 0; i < 3; i++ {
+		if ok, _ := s.Take("A", 1, 3); !ok {
+			t.Errorf("Token #%d has not been granted.", i)
+		}
+	}
+
+	ok, wait := s.Take("A", 1, 3)
+	if ok || wait <= 0 || wait > time.Second {
+		t.Errorf("Empty bucket granted a token: %t/%s", ok, wait)
+	}
+
+	if ok, _ := s.Take("B", 1, 3); !ok {
+		t.Errorf("Buckets are not separated by key.")
+	}
+
+	if ok, _ := s.Take("C", 1000, 1); !ok {
+		t.Errorf("Token has not been granted.")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if ok, _ := s.Take("C", 1000, 1); !ok {
+		t.Errorf("Bucket has not been refilled.")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func() string { return "A" }, "Limited", "A")
+	co.ExposeFunction(func() string { return "B" }, "Limited", "B")
+	co.Interface("Limited").Bindings().RateLimit(RateLimit{Name: "limited", Rate: 0.001, Burst: 2})
+	h := co.Setup()
+
+	get := func(m, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/gotojs/Limited/"+m, nil)
+		req.RemoteAddr = ip + ":1234"
+		return record(h, req)
+	}
+
+	if get("A", "10.0.0.1").Code != http.StatusOK || get("B", "10.0.0.1").Code != http.StatusOK {
+		t.Errorf("Calls within the limit have been rejected.")
+	}
+
+	r := get("A", "10.0.0.1")
+	if r.Code != http.StatusTooManyRequests {
+		t.Errorf("Call exceeding the shared limit has not been rejected: %d/%d", r.Code, http.StatusTooManyRequests)
+	}
+
+	if len(r.Header().Get("Retry-After")) == 0 {
+		t.Errorf("Retry-After header is missing.")
+	}
+
+	if get("A", "10.0.0.2").Code != http.StatusOK {
+		t.Errorf("Limit is not keyed by remote ip.")
+	}
+
+	if s := co.RateLimitStats()["limited"]; s.Allowed != 3 || s.Rejected != 1 {
+		t.Errorf("Invalid rate limit stats: %d/%d allowed, %d/%d rejected", s.Allowed, 3, s.Rejected, 1)
+	}
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.SetAPIKeyStore(NewMemoryAPIKeyStore(&APIKey{Key: "k1", Owner: "partner"}))
+	co.ExposeFunction(func() string { return "S" }, "Limited", "Session").RateLimit(RateLimit{Rate: 0.001, Burst: 1, Key: SessionIDKey})
+	co.ExposeFunction(func() string { return "K" }, "Limited", "Key").RateLimit(RateLimit{Rate: 0.001, Burst: 1, Key: APIKeyKey})
+	h := co.Setup()
+
+	get := func(path string, cookies []*http.Cookie, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		if len(header) > 0 {
+			req.Header.Set(header[0], header[1])
+		}
+		return record(h, req)
+	}
+
+	r := get("/gotojs/Limited/Session", nil)
+	alice := r.Result().Cookies()
+	if r.Code != http.StatusOK || len(alice) != 1 {
+		t.Fatalf("Session has not been created: %d", r.Code)
+	}
+	if r := get("/gotojs/Limited/Session", alice); r.Code != http.StatusOK {
+		t.Errorf("Limit is not keyed by session: %d", r.Code)
+	}
+	if r := get("/gotojs/Limited/Session", alice); r.Code != http.StatusTooManyRequests {
+		t.Errorf("Call exceeding the session limit has not been rejected: %d", r.Code)
+	}
+	if r := get("/gotojs/Limited/Session", nil); r.Code != http.StatusTooManyRequests {
+		t.Errorf("Call without session cookie got a fresh bucket: %d", r.Code)
+	}
+
+	if r := get("/gotojs/Limited/Key", nil, DefaultHeaderAPIKey, "k1"); r.Code != http.StatusOK {
+		t.Errorf("Call within the limit has been rejected: %d", r.Code)
+	}
+	if r := get("/gotojs/Limited/Key", nil, DefaultHeaderAPIKey, "k1"); r.Code != http.StatusTooManyRequests {
+		t.Errorf("Call exceeding the api key limit has not been rejected: %d", r.Code)
+	}
+	if r := get("/gotojs/Limited/Key", nil, DefaultHeaderAPIKey, "rotated"); r.Code != http.StatusUnauthorized {
+		t.Errorf("Unknown api key got a fresh bucket: %d", r.Code)
+	}
+}
@@ -0,0 +1,309 @@
+package gotojs
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default values of the response cache.
+const (
+	DefaultCacheSize = 1000 // in count of cached responses
+)
+
+// KeyFunc derives a partial key from the injections of a call. It is used to distinguish
+// calls with identical arguments but different callers, like the currently logged in user.
+type KeyFunc func(Injections) string
+
+// SessionKey returns a KeyFunc that derives the key from the given session properties.
+func SessionKey(names ...string) KeyFunc {
+	return func(inj Injections) string {
+		s, _ := inj[typeOfSession].(*Session)
+		if s == nil || s.Properties == nil {
+			return ""
+		}
+		vals := make([]string, len(names))
+		for i, n := range names {
+			vals[i] = s.Get(n)
+		}
+		return strings.Join(vals, "\x00")
+	}
+}
+
+// HeaderKey returns a KeyFunc that derives the key from the given request headers.
+func HeaderKey(names ...string) KeyFunc {
+	return func(inj Injections) string {
+		hc, _ := inj[typeOfHTTPContext].(*HTTPContext)
+		if hc == nil || hc.Request == nil {
+			return ""
+		}
+		vals := make([]string, len(names))
+		for i, n := range names {
+			vals[i] = hc.Request.Header.Get(n)
+		}
+		return strings.Join(vals, "\x00")
+	}
+}
+
+// CallerKey is a KeyFunc that derives the key from the identity of the caller. These are the ID
+// of the session sent by the caller, the authenticated principal, its claims and the validated
+// api key of the call.
+func CallerKey(inj Injections) string {
+	var parts []string
+	if s, _ := inj[typeOfSession].(*Session); s != nil && s.sent {
+		parts = append(parts, "session:"+s.ID())
+	}
+	if p, _ := inj[typeOfPrincipal].(*Principal); p != nil {
+		parts = append(parts, "principal:"+p.Name)
+	}
+	if c, _ := inj[typeOfClaims].(Claims); c != nil {
+		if cb, err := json.Marshal(c); err == nil {
+			parts = append(parts, "claims:"+string(cb))
+		}
+	}
+	if k, _ := inj[typeOfAPIKey].(*APIKey); k != nil {
+		parts = append(parts, "apikey:"+k.Key)
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// callKey compiles a key which identifies a call of the binding by its arguments and the
+// partial keys derived from the injections. Calls of bindings receiving the identity of the
+// caller are additionally keyed by CallerKey.
+func callKey(b Binding, inj Injections, args []interface{}, vary []KeyFunc) string {
+	if b.perCaller() {
+		vary = append(vary[:len(vary):len(vary)], CallerKey)
+	}
+	ab, err := json.Marshal(args)
+	if err != nil {
+		panic(fmt.Errorf("Could not compile call key for \"%s\": %s", b.Name(), err))
+	}
+	key := b.Name() + "\x00" + string(ab)
+	for _, kf := range vary {
+		key += "\x00" + kf(inj)
+	}
+	return key
+}
+
+// CachePolicy declares how the responses of a binding are cached. Only calls via GET are
+// served from the cache.
+type CachePolicy struct {
+	// TTL defines how long a response is valid.
+	TTL time.Duration
+
+	// Vary defines further key parts derived from the injections. If any is given, responses
+	// are declared as private towards HTTP caches. They are private as well if the binding
+	// receives the session, principal, claims or api key of the caller, whose responses are
+	// additionally kept per caller, see CallerKey.
+	Vary []KeyFunc
+}
+
+// Types of the injections that identify the caller.
+var (
+	typeOfPrincipal = reflect.TypeOf(&Principal{})
+	typeOfClaims    = reflect.TypeOf(Claims(nil))
+)
+
+// perCaller checks whether the binding receives injections that identify the caller.
+func (b Binding) perCaller() bool {
+	bb := b.base()
+	for _, t := range bb.injections {
+		switch t {
+		case typeOfSession, typeOfPrincipal, typeOfClaims, typeOfAPIKey, bb.container.sessionType:
+			return true
+		}
+	}
+	return false
+}
+
+// cacheControl returns the Cache-Control header value of the policy for the given binding.
+func (p *CachePolicy) cacheControl(b Binding) string {
+	scope := "public"
+	if len(p.Vary) > 0 || b.perCaller() {
+		scope = "private"
+	}
+	return fmt.Sprintf("%s, max-age=%d", scope, int(p.TTL.Seconds()))
+}
+
+// cachedResponse is a single entry of the response cache.
+type cachedResponse struct {
+	key     string
+	binding string
+	mime    string
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// responseCache is a size bounded in-memory store of encoded binding responses. If the
+// maximum count of entries is reached, the least recently used entry is evicted.
+type responseCache struct {
+	entries    map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+	mutex      sync.Mutex
+}
+
+// newResponseCache creates a new response cache with the given maximum amount of entries.
+func newResponseCache(maxEntries int) *responseCache {
+	return &responseCache{
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries}
+}
+
+// get looks up a valid cache entry.
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	el, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	e := el.Value.(*cachedResponse)
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e, true
+}
+
+// put adds a response to the cache and evicts the least recently used entries if necessary.
+func (c *responseCache) put(key, binding, mime string, body []byte, ttl time.Duration) *cachedResponse {
+	sum := sha1.Sum(body)
+	e := &cachedResponse{
+		key:     key,
+		binding: binding,
+		mime:    mime,
+		body:    body,
+		etag:    "\"" + hex.EncodeToString(sum[:]) + "\"",
+		expires: time.Now().Add(ttl)}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if el, found := c.entries[key]; found {
+		c.remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+	return e
+}
+
+// remove deletes a list element from the cache. The cache must be locked.
+func (c *responseCache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cachedResponse).key)
+}
+
+// invalidate removes all entries whose binding name matches the given function.
+func (c *responseCache) invalidate(match func(string) bool) (count int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if match(el.Value.(*cachedResponse).binding) {
+			c.remove(el)
+			count++
+		}
+		el = next
+	}
+	return
+}
+
+// len returns the amount of cached responses.
+func (c *responseCache) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+// Cache declares a cache policy for the binding. Responses of GET calls are kept in the
+// response cache of the container and are answered with an ETag and Cache-Control header.
+func (b Binding) Cache(p CachePolicy) Binding {
+	b.base().cachePolicy = &p
+	return b
+}
+
+// Cache declares the cache policy for all given bindings. See Binding.Cache for more information.
+func (bs Bindings) Cache(p CachePolicy) Bindings {
+	for _, b := range bs {
+		b.Cache(p)
+	}
+	return bs
+}
+
+// InvalidateCache removes all cached responses of the binding.
+func (b Binding) InvalidateCache() int {
+	n := b.Name()
+	return b.base().container.responseCache.invalidate(func(bn string) bool { return bn == n })
+}
+
+// InvalidateCache removes all cached responses of bindings whose name matches the given
+// regex pattern. The amount of removed responses is returned.
+func (b *Container) InvalidateCache(pattern string) int {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Printf("Compilation of regexp patter \"%s\" failed: %s", pattern, err.Error())
+		return 0
+	}
+	return b.responseCache.invalidate(re.MatchString)
+}
+
+// matchETag checks whether the If-None-Match header matches the given entity tag.
+func matchETag(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// processCached is an internally used method to process a call of a binding with a cache policy.
+// The response is taken from the cache if existing and answered with 304 if the client already
+// knows the current entity.
+func (f Binding) processCached(hc *HTTPContext, out io.Writer, injs Injections, args ...interface{}) (mime string) {
+	p := f.base().cachePolicy
+	inj := f.mergeInjections(injs)
+	if !f.filter(inj) {
+		return
+	}
+
+	rc := f.base().container.responseCache
+	key := callKey(f, inj, args, p.Vary)
+	e, found := rc.get(key)
+	if !found {
+		buf := new(bytes.Buffer)
+		mime = encodeResult(buf, f.invoke(inj, args))
+		e = rc.put(key, f.Name(), mime, buf.Bytes(), p.TTL)
+	}
+
+	h := hc.Response.Header()
+	h.Set("ETag", e.etag)
+	h.Set("Cache-Control", p.cacheControl(f))
+
+	if matchETag(hc.Request.Header.Get("If-None-Match"), e.etag) {
+		hc.ReturnStatus = http.StatusNotModified
+		return e.mime
+	}
+
+	if _, err := out.Write(e.body); err != nil {
+		panic(err)
+	}
+	return e.mime
+}
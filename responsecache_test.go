@@ -0,0 +1,145 @@
+package gotojs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// record serves the given request by the handler and returns the recorded response.
+func record(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func cachedGet(h http.Handler, url, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", url, nil)
+	if len(etag) > 0 {
+		req.Header.Set("If-None-Match", etag)
+	}
+	return record(h, req)
+}
+
+func TestResponseCache(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	calls := 0
+	co.ExposeFunction(func(s string) string {
+		calls++
+		return "Hello " + s
+	}, "Cache", "Hello").Cache(CachePolicy{TTL: time.Minute})
+	h := co.Setup()
+
+	r1 := cachedGet(h, "/gotojs/Cache/Hello/World", "")
+	r2 := cachedGet(h, "/gotojs/Cache/Hello/World", "")
+	if calls != 1 {
+		t.Errorf("Cached binding was invoked more than once: %d/%d", calls, 1)
+	}
+
+	etag := r1.Header().Get("ETag")
+	if len(etag) == 0 || etag != r2.Header().Get("ETag") {
+		t.Errorf("Invalid ETag: '%s'/'%s'", etag, r2.Header().Get("ETag"))
+	}
+
+	if cc := r1.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Errorf("Invalid Cache-Control header: %s", cc)
+	}
+
+	if b, _ := ioutil.ReadAll(r2.Body); string(b) != "\"Hello World\"" {
+		t.Errorf("Invalid cached response: %s", b)
+	}
+
+	if r := cachedGet(h, "/gotojs/Cache/Hello/World", etag); r.Code != http.StatusNotModified || r.Body.Len() > 0 {
+		t.Errorf("Conditional GET failed: %d/%d", r.Code, http.StatusNotModified)
+	}
+
+	cachedGet(h, "/gotojs/Cache/Hello/Earth", "")
+	if calls != 2 {
+		t.Errorf("Different arguments must not share a cache entry: %d/%d", calls, 2)
+	}
+
+	if n := co.InvalidateCache(`^Cache\.`); n != 2 {
+		t.Errorf("Invalid count of invalidated entries: %d/%d", n, 2)
+	}
+
+	cachedGet(h, "/gotojs/Cache/Hello/World", "")
+	if calls != 3 {
+		t.Errorf("Invalidated entry has been served from cache: %d/%d", calls, 3)
+	}
+}
+
+func TestResponseCacheVary(t *testing.T) {
+	co := NewContainer()
+	b := co.ExposeFunction(func(s *Session) string { return s.Get("user") }, "Cache", "User").
+		Cache(CachePolicy{TTL: time.Minute, Vary: []KeyFunc{SessionKey("user")}})[0]
+
+	alice, bob := NewSession(), NewSession()
+	alice.Set("user", "alice")
+	bob.Set("user", "bob")
+
+	inj := b.mergeInjections(NewI(alice))
+	if callKey(b, inj, nil, b.base().cachePolicy.Vary) == callKey(b, b.mergeInjections(NewI(bob)), nil, b.base().cachePolicy.Vary) {
+		t.Errorf("Cache key does not respect the session.")
+	}
+
+	if cc := b.base().cachePolicy.cacheControl(b); cc != "private, max-age=60" {
+		t.Errorf("Invalid Cache-Control header: %s", cc)
+	}
+
+	for _, f := range []interface{}{
+		func(p *Principal) string { return "" },
+		func(c Claims) string { return "" },
+		func(s *Session) string { return "" }} {
+		b := co.ExposeFunction(f, "Cache", "Caller").Cache(CachePolicy{TTL: time.Minute})[0]
+		if cc := b.base().cachePolicy.cacheControl(b); cc != "private, max-age=60" {
+			t.Errorf("Response for the caller is not private: %s", cc)
+		}
+	}
+}
+
+func TestResponseCacheCaller(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(s *Session, u string) string { s.Set("user", u); return u }, "Cache", "Login")
+	co.ExposeFunction(func(s *Session) string { return "user=" + s.Get("user") }, "Cache", "Whoami").
+		Cache(CachePolicy{TTL: time.Minute})
+	h := co.Setup()
+
+	whoami := func(u string) string {
+		cookies := cachedGet(h, "/gotojs/Cache/Login/"+u, "").Result().Cookies()
+		req := httptest.NewRequest("GET", "/gotojs/Cache/Whoami", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return record(h, req).Body.String()
+	}
+
+	if r := whoami("alice"); r != `"user=alice"` {
+		t.Errorf("Invalid response for the first caller: %s", r)
+	}
+	if r := whoami("bob"); r != `"user=bob"` {
+		t.Errorf("Cached response has been served to another caller: %s", r)
+	}
+}
+
+func TestResponseCacheBound(t *testing.T) {
+	rc := newResponseCache(2)
+	rc.put("a", "X.a", DefaultMimeType, []byte("1"), time.Minute)
+	rc.put("b", "X.b", DefaultMimeType, []byte("2"), time.Minute)
+	rc.get("a")
+	rc.put("c", "X.c", DefaultMimeType, []byte("3"), time.Minute)
+
+	if rc.len() != 2 {
+		t.Errorf("Cache size exceeded: %d/%d", rc.len(), 2)
+	}
+
+	if _, found := rc.get("b"); found {
+		t.Errorf("Least recently used entry has not been evicted.")
+	}
+
+	rc.put("d", "X.d", DefaultMimeType, []byte("4"), -time.Second)
+	if _, found := rc.get("d"); found {
+		t.Errorf("Expired entry has been returned.")
+	}
+}
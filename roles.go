@@ -0,0 +1,117 @@
+package gotojs
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultSessionRoles is the session property that holds the roles of the session.
+const DefaultSessionRoles = "gotojs.roles"
+
+// Roles returns the roles that have been assigned to the session.
+func (s *Session) Roles() []string {
+	v := s.Get(DefaultSessionRoles)
+	if len(v) == 0 {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// SetRoles assigns the given roles to the session. Without roles, the assignment is removed.
+func (s *Session) SetRoles(roles ...string) {
+	if len(roles) == 0 {
+		s.Delete(DefaultSessionRoles)
+		return
+	}
+	s.Set(DefaultSessionRoles, strings.Join(roles, ","))
+}
+
+// Roles returns the sorted roles of the caller. These are the roles of the authenticated
+// principal joined with the roles of the given session.
+func (c *HTTPContext) Roles(s *Session) (ret []string) {
+	if c.Principal != nil {
+		ret = append(ret, c.Principal.Roles...)
+	}
+	if s != nil {
+		for _, r := range s.Roles() {
+			if !ContainsS(ret, r) {
+				ret = append(ret, r)
+			}
+		}
+	}
+	sort.Strings(ret)
+	return
+}
+
+// Require declares roles the caller must own in order to call the binding. Multiple calls add
+// further roles. Callers without any role are answered with status 401, callers missing one of
+// the roles with status 403. The binding is hidden from the engine of such callers.
+func (b Binding) Require(roles ...string) Binding {
+	bb := b.base()
+	bb.roles = append(bb.roles, roles...)
+	bb.container.revision++
+	return b
+}
+
+// Require declares roles the caller must own in order to call any of the given bindings.
+func (bs Bindings) Require(roles ...string) Bindings {
+	for _, b := range bs {
+		b.Require(roles...)
+	}
+	return bs
+}
+
+// Require declares roles the caller must own in order to call any binding of the named
+// interface. This also applies to bindings that are exposed later on.
+func (b *Container) Require(in string, roles ...string) {
+	b.interfaceRoles[in] = append(b.interfaceRoles[in], roles...)
+	b.revision++
+}
+
+// RequiredRoles returns all roles that are required to call the binding. This includes
+// the roles required by its interface.
+func (b Binding) RequiredRoles() (ret []string) {
+	bb := b.base()
+	ret = append(ret, bb.container.interfaceRoles[bb.interfaceName]...)
+	return append(ret, bb.roles...)
+}
+
+// Allowed returns true if a caller owning the given roles may call the binding.
+func (b Binding) Allowed(roles []string) bool {
+	for _, r := range b.RequiredRoles() {
+		if !ContainsS(roles, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// permitted returns a function reporting whether the caller of the given context may call a
+// binding. The roles of the caller are resolved once. Calls that are not made via HTTP may call
+// any binding.
+func (b *Container) permitted(hc *HTTPContext) func(Binding) bool {
+	if hc == nil || hc.Request == nil {
+		return func(Binding) bool { return true }
+	}
+	roles := hc.Roles(hc.Session(b.keys...))
+	return func(bi Binding) bool { return bi.Allowed(roles) }
+}
+
+// permit enforces the required roles of the binding.
+func (b Binding) permit(hc *HTTPContext, s *Session) {
+	required := b.RequiredRoles()
+	if len(required) == 0 {
+		return
+	}
+
+	roles := hc.Roles(s)
+	if len(roles) == 0 {
+		b.base().container.challenge(hc)
+		hc.Errorf(http.StatusUnauthorized, "Authentication required for \"%s\".", b.Name())
+	}
+
+	if !b.Allowed(roles) {
+		hc.Errorf(http.StatusForbidden, "Access to \"%s\" denied. Required roles: %s", b.Name(), strings.Join(required, ","))
+	}
+}
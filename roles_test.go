@@ -0,0 +1,97 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func roleContainer() *Container {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.Authenticate(&BasicAuthenticator{Verify: func(u, p string) *Principal {
+		switch u {
+		case "alice":
+			return &Principal{Name: u, Roles: []string{"admin", "user"}}
+		case "bob":
+			return &Principal{Name: u, Roles: []string{"user"}}
+		}
+		return nil
+	}})
+	co.ExposeYourself()
+	co.ExposeFunction(func() string { return "public" }, "Public", "Hello")
+	co.ExposeFunction(func() string { return "secret" }, "Admin", "Secret").Require("admin")
+	co.Require("Reports", "user")
+	co.ExposeFunction(func() string { return "daily" }, "Reports", "Daily")
+	return co
+}
+
+func roleGet(h http.Handler, url, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", url, nil)
+	if len(user) > 0 {
+		req.SetBasicAuth(user, "")
+	}
+	return record(h, req)
+}
+
+func TestRequire(t *testing.T) {
+	h := roleContainer().Setup()
+
+	cases := []struct {
+		url, user string
+		status    int
+	}{
+		{"/gotojs/Public/Hello", "", http.StatusOK},
+		{"/gotojs/Admin/Secret", "", http.StatusUnauthorized},
+		{"/gotojs/Admin/Secret", "bob", http.StatusForbidden},
+		{"/gotojs/Admin/Secret", "alice", http.StatusOK},
+		{"/gotojs/Reports/Daily", "", http.StatusUnauthorized},
+		{"/gotojs/Reports/Daily", "bob", http.StatusOK},
+	}
+
+	for _, c := range cases {
+		if r := roleGet(h, c.url, c.user); r.Code != c.status {
+			t.Errorf("Invalid status for %s as '%s': %d/%d", c.url, c.user, r.Code, c.status)
+		}
+	}
+}
+
+func TestRequireHidesBindings(t *testing.T) {
+	co := roleContainer()
+	h := co.Setup()
+
+	if e := roleGet(h, "/gotojs/", "bob").Body.String(); strings.Contains(e, "Secret") || !strings.Contains(e, "Daily") {
+		t.Errorf("Engine does not respect the roles of the caller.")
+	}
+
+	if e := roleGet(h, "/gotojs/", "alice").Body.String(); !strings.Contains(e, "Secret") {
+		t.Errorf("Engine is missing an allowed binding.")
+	}
+
+	if e := roleGet(h, "/gotojs/", "").Body.String(); strings.Contains(e, "Reports") {
+		t.Errorf("Engine contains an interface without allowed bindings.")
+	}
+
+	if b := roleGet(h, "/gotojs/gotojs/Bindings", "bob").Body.String(); strings.Contains(b, "Admin.Secret") || !strings.Contains(b, "Reports.Daily") {
+		t.Errorf("Introspection does not respect the roles of the caller: %s", b)
+	}
+
+	if list := co.Invoke(DefaultInternalInterfaceName, "Bindings").(map[string]string); len(list) != 6 {
+		t.Errorf("Local introspection must not be restricted: %d/%d", len(list), 6)
+	}
+}
+
+func TestSessionRoles(t *testing.T) {
+	s := NewSession()
+	s.SetRoles("user", "admin")
+	hc := &HTTPContext{Principal: &Principal{Roles: []string{"user", "guest"}}}
+
+	if r := strings.Join(hc.Roles(s), ","); r != "admin,guest,user" {
+		t.Errorf("Invalid caller roles: %s/%s", r, "admin,guest,user")
+	}
+
+	s.SetRoles()
+	if len(s.Roles()) != 0 {
+		t.Errorf("Session roles have not been removed.")
+	}
+}
@@ -0,0 +1,196 @@
+package gotojs
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PathParams contains the named parameters of a route taken from the request path. It will be
+// injected whenever a binding called by a route declares a parameter of type PathParams.
+type PathParams map[string]string
+
+// Route maps a HTTP method and a path template like "/users/{id}" to a binding. The named
+// parameters of the template are passed as the first arguments of the binding in the order
+// they appear in the template. They are followed by the parameters of the query string and the
+// JSON body. A body which is not a JSON array is passed as a single argument.
+type Route struct {
+	Method   string
+	Template string
+	Binding  Binding
+	segments []string
+}
+
+// Params returns the names of the parameters of the route template.
+func (rt *Route) Params() (ret []string) {
+	for _, s := range rt.segments {
+		if isParamSegment(s) {
+			ret = append(ret, s[1:len(s)-1])
+		}
+	}
+	return
+}
+
+// String returns the method and template of the route like "GET /users/{id}".
+func (rt *Route) String() string {
+	return rt.Method + " " + rt.Template
+}
+
+// isParamSegment checks whether the path segment is a named parameter like "{id}".
+func isParamSegment(s string) bool {
+	return len(s) > 2 && strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")
+}
+
+// splitPath splits a path into its segments.
+func splitPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if len(p) == 0 {
+		return []string{}
+	}
+	return strings.Split(p, "/")
+}
+
+// match matches the path against the route template and returns the named parameters.
+func (rt *Route) match(path string) (PathParams, bool) {
+	segs := splitPath(path)
+	if len(segs) != len(rt.segments) {
+		return nil, false
+	}
+	params := make(PathParams)
+	for i, s := range rt.segments {
+		if isParamSegment(s) {
+			params[s[1:len(s)-1]] = segs[i]
+		} else if s != segs[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// args returns the named parameters in the order of the route template.
+func (p PathParams) args(rt *Route) (ret []interface{}) {
+	for _, n := range rt.Params() {
+		ret = append(ret, p[n])
+	}
+	return
+}
+
+// Route publishes the binding by the given HTTP method and path template. The path template is
+// absolute and not part of the gotojs context. Routes are dispatched by the container before
+// the handlers registered at its muxer, so they take precedence over file server, static or
+// redirect handlers of the same path. It panics if the binding declares HTTP methods that do not
+// include the method of the route. Methods declared after the route are enforced as well and
+// answer the route with status 405.
+func (b *Container) Route(method, template string, bi Binding) *Route {
+	rt := &Route{
+		Method:   strings.ToUpper(method),
+		Template: "/" + strings.Trim(template, "/"),
+		Binding:  bi,
+		segments: splitPath(template)}
+
+	for _, s := range rt.segments {
+		if strings.ContainsAny(s, "{}") && !isParamSegment(s) {
+			panic(fmt.Errorf("Invalid route template \"%s\".", template))
+		}
+	}
+
+	if ms := bi.base().methods; len(ms) > 0 && !ContainsS(ms, rt.Method) {
+		panic(fmt.Errorf("Route \"%s\" conflicts with the methods %s of binding \"%s\".", rt, strings.Join(ms, ", "), bi.Name()))
+	}
+
+	b.routes = append(b.routes, rt)
+	return rt
+}
+
+// isRoute checks whether the path matches any route regardless of its method.
+func (b *Container) isRoute(path string) bool {
+	for _, rt := range b.routes {
+		if _, ok := rt.match(path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// handler returns the handler of the request. Routes take precedence over the mounted
+// containers and the handlers registered at the muxer. Requests not handled by this container
+// are passed to the mounted containers.
+func (b *Container) handler(r *http.Request) (http.Handler, bool) {
+	if b.isRoute(r.URL.Path) {
+		return http.HandlerFunc(b.serve), true
+	}
+	if sub := b.contextOf(r.URL.Path); sub != nil {
+		return http.HandlerFunc(sub.serve), true
+	}
+	if h, pattern := b.ServeMux.Handler(r); len(pattern) > 0 {
+		return h, true
+	}
+	for _, m := range b.mounts {
+		if h, found := m.sub.handler(r); found {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+// ServeHTTP dispatches the routes and the mounted containers and passes any other request to
+// the muxer.
+func (b *Container) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h, found := b.handler(r); found {
+		h.ServeHTTP(w, r)
+		return
+	}
+	b.ServeMux.ServeHTTP(w, r)
+}
+
+// Routes returns all declared routes.
+func (b *Container) Routes() []*Route {
+	return b.routes
+}
+
+// Resource publishes the bindings of the named interface as REST resource at the given path.
+// Bindings are mapped by their method name as follows:
+//
+//	List:   GET    /path
+//	Create: POST   /path
+//	Get:    GET    /path/{id}
+//	Update: PUT    /path/{id}
+//	Delete: DELETE /path/{id}
+//
+// Bindings that do not exist are ignored.
+func (b *Container) Resource(path, in string) (ret []*Route) {
+	path = "/" + strings.Trim(path, "/")
+	mapping := []struct{ mn, method, template string }{
+		{"List", "GET", path},
+		{"Create", "POST", path},
+		{"Get", "GET", path + "/{id}"},
+		{"Update", "PUT", path + "/{id}"},
+		{"Delete", "DELETE", path + "/{id}"}}
+
+	for _, m := range mapping {
+		if bi, found := b.Binding(in, m.mn); found {
+			ret = append(ret, b.Route(m.method, m.template, bi))
+		}
+	}
+	return
+}
+
+// matchRoute searches the route of the request. If the path matches a route but the method
+// does not, the request is answered with status 405.
+func (f *Container) matchRoute(hc *HTTPContext) (*Route, PathParams) {
+	var allowed []string
+	for _, rt := range f.routes {
+		if params, ok := rt.match(hc.Request.URL.Path); ok {
+			if rt.Method == hc.Request.Method {
+				return rt, params
+			}
+			allowed = append(allowed, rt.Method)
+		}
+	}
+
+	if len(allowed) > 0 {
+		hc.Response.Header().Set("Allow", strings.Join(allowed, ", "))
+		hc.Errorf(http.StatusMethodNotAllowed, "Method %s not allowed for \"%s\".", hc.Request.Method, hc.Request.URL.Path)
+	}
+	return nil, nil
+}
@@ -0,0 +1,141 @@
+package gotojs
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type restUser struct {
+	ID   int
+	Name string
+}
+
+type restUsers struct {
+	users map[int]*restUser
+	next  int
+}
+
+func (s *restUsers) List() (ret []*restUser) {
+	for i := 1; i < s.next; i++ {
+		if u, ok := s.users[i]; ok {
+			ret = append(ret, u)
+		}
+	}
+	return
+}
+
+func (s *restUsers) Get(id int) *restUser { return s.users[id] }
+
+func (s *restUsers) Create(u restUser) *restUser {
+	u.ID = s.next
+	s.next++
+	s.users[u.ID] = &u
+	return &u
+}
+
+func (s *restUsers) Delete(id int) bool {
+	_, found := s.users[id]
+	delete(s.users, id)
+	return found
+}
+
+func TestResource(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeYourself()
+	co.ExposeInterface(&restUsers{users: make(map[int]*restUser), next: 1}, "Users")
+	if rs := co.Resource("/users", "Users"); len(rs) != 4 {
+		t.Errorf("Invalid count of routes: %d/%d", len(rs), 4)
+	}
+	h := co.Setup()
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		return record(h, req)
+	}
+
+	if r := call("POST", "/users", `{"Name":"alice"}`); r.Code != http.StatusOK || !strings.Contains(r.Body.String(), `"ID":1`) {
+		t.Errorf("Resource could not be created: %d %s", r.Code, r.Body.String())
+	}
+	call("POST", "/users", `{"Name":"bob"}`)
+
+	if r := call("GET", "/users/2", ""); !strings.Contains(r.Body.String(), `"Name":"bob"`) {
+		t.Errorf("Resource could not be read: %d %s", r.Code, r.Body.String())
+	}
+
+	if r := call("DELETE", "/users/1", ""); r.Body.String() != "true" {
+		t.Errorf("Resource could not be deleted: %d %s", r.Code, r.Body.String())
+	}
+
+	if r := call("GET", "/users", ""); strings.Contains(r.Body.String(), "alice") || !strings.Contains(r.Body.String(), "bob") {
+		t.Errorf("Resources could not be listed: %s", r.Body.String())
+	}
+
+	r := call("PUT", "/users/2", `{"Name":"carol"}`)
+	if r.Code != http.StatusMethodNotAllowed || r.Header().Get("Allow") != "GET, DELETE" {
+		t.Errorf("Undeclared method has not been rejected: %d '%s'", r.Code, r.Header().Get("Allow"))
+	}
+
+	if r := call("GET", "/gotojs/gotojs/Routes", ""); !strings.Contains(r.Body.String(), `"GET /users/{id}":"Users.Get"`) {
+		t.Errorf("Routes are missing in the introspection: %s", r.Body.String())
+	}
+}
+
+func TestRoutePathParams(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	b := co.ExposeFunction(func(p PathParams, org string, id int) string {
+		return fmt.Sprintf("%s/%d/%s", org, id, p["org"])
+	}, "Orgs", "Member")[0]
+	rt := co.Route("get", "/orgs/{org}/members/{id}", b)
+	h := co.Setup()
+
+	if p := strings.Join(rt.Params(), ","); p != "org,id" {
+		t.Errorf("Invalid route parameters: %s", p)
+	}
+
+	if r := record(h, httptest.NewRequest("GET", "/orgs/acme/members/7", nil)); r.Body.String() != `"acme/7/acme"` {
+		t.Errorf("Path parameters have not been passed: %d %s", r.Code, r.Body.String())
+	}
+
+	if r := record(h, httptest.NewRequest("GET", "/orgs/acme/teams/7", nil)); r.Code != http.StatusNotFound {
+		t.Errorf("Unknown route has been found: %d", r.Code)
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.HandleStatic("/static/", "static")
+	co.Redirect("/old", "/new")
+	b := co.ExposeFunction(func(id string) string { return "item " + id }, "Items", "Get")[0]
+	co.Route("GET", "/static/items/{id}", b)
+	co.Route("GET", "/{id}", b)
+	h := co.Setup()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		return record(h, httptest.NewRequest("GET", path, nil))
+	}
+
+	if r := get("/static/items/1"); r.Body.String() != `"item 1"` {
+		t.Errorf("Route below a static handler has not been dispatched: %d %s", r.Code, r.Body.String())
+	}
+	if r := get("/static/index.html"); r.Body.String() != "static" {
+		t.Errorf("Static handler has been hidden by a route: %s", r.Body.String())
+	}
+	if r := get("/2"); r.Body.String() != `"item 2"` {
+		t.Errorf("Route starting with a parameter has not been dispatched: %s", r.Body.String())
+	}
+	if r := get("/gotojs/Items/Get/3"); r.Body.String() != `"item 3"` {
+		t.Errorf("Binding is not reachable by the gotojs context: %s", r.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Route conflicting with the methods of the binding has been accepted.")
+		}
+	}()
+	co.Route("DELETE", "/items/{id}", b.Read())
+}
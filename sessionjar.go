@@ -0,0 +1,207 @@
+package gotojs
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSessionCookieJar is the session property that holds the cookies of remote bindings.
+const DefaultSessionCookieJar = "gotojs.jar"
+
+// jarEntry is a cookie stored in the cookie jar of a session.
+type jarEntry struct {
+	Name     string    `json:"n"`
+	Value    string    `json:"v"`
+	Domain   string    `json:"d"`
+	Path     string    `json:"p"`
+	HostOnly bool      `json:"h,omitempty"`
+	Secure   bool      `json:"s,omitempty"`
+	HttpOnly bool      `json:"o,omitempty"`
+	Expires  time.Time `json:"e,omitempty"`
+	Created  time.Time `json:"c"`
+}
+
+// expired returns true if the cookie has expired. Cookies without expiry live as long as the session.
+func (e *jarEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// id identifies a cookie by its name, domain and path.
+func (e *jarEntry) id() string {
+	return e.Name + ";" + e.Domain + ";" + e.Path
+}
+
+// jar decodes the cookie jar of the session.
+func (s *Session) jar() (ret []*jarEntry) {
+	if err := s.Value(DefaultSessionCookieJar, &ret); err != nil {
+		log.Printf("%s. Dropping cookie jar.", err)
+		return nil
+	}
+	return
+}
+
+// setJar stores the cookie jar in the session and drops expired cookies.
+func (s *Session) setJar(entries []*jarEntry) {
+	now := time.Now()
+	valid := make([]*jarEntry, 0, len(entries))
+	for _, e := range entries {
+		if !e.expired(now) {
+			valid = append(valid, e)
+		}
+	}
+
+	if len(valid) == 0 {
+		if _, found := s.Properties[DefaultSessionCookieJar]; found {
+			s.Delete(DefaultSessionCookieJar)
+		}
+		return
+	}
+
+	raw, _ := json.Marshal(valid)
+	if s.Get(DefaultSessionCookieJar) != string(raw) {
+		s.Set(DefaultSessionCookieJar, string(raw))
+	}
+}
+
+// cookieHost returns the canonical host of the url.
+func cookieHost(u *url.URL) string {
+	host := u.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// domainMatch checks whether the host domain-matches the cookie domain as defined by RFC 6265
+// section 5.1.3.
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// defaultCookiePath returns the default path of a cookie as defined by RFC 6265 section 5.1.4.
+func defaultCookiePath(p string) string {
+	if len(p) == 0 || p[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(p, "/")
+	if i == 0 {
+		return "/"
+	}
+	return p[:i]
+}
+
+// pathMatch checks whether the request path path-matches the cookie path as defined by
+// RFC 6265 section 5.1.4.
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// SetCookies stores the cookies received from the given url in the cookie jar of the session.
+// This way, a session serves as http.CookieJar of remote bindings. The cookies are kept apart
+// from the session properties and follow the rules of RFC 6265. Public suffixes are not
+// taken into account.
+func (s *Session) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	host := cookieHost(u)
+	if len(host) == 0 {
+		return
+	}
+
+	now := time.Now()
+	entries := s.jar()
+	for _, c := range cookies {
+		e := &jarEntry{
+			Name:     c.Name,
+			Value:    c.Value,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			Created:  now}
+
+		// Domain
+		if d := strings.ToLower(strings.TrimPrefix(c.Domain, ".")); len(d) == 0 {
+			e.Domain, e.HostOnly = host, true
+		} else if domainMatch(host, d) {
+			e.Domain = d
+		} else {
+			continue // Cookies for foreign domains are rejected.
+		}
+
+		// Path
+		if e.Path = c.Path; len(e.Path) == 0 || e.Path[0] != '/' {
+			e.Path = defaultCookiePath(u.Path)
+		}
+
+		// Expiry: Max-Age takes precedence over Expires.
+		if c.MaxAge < 0 {
+			e.Expires = now
+		} else if c.MaxAge > 0 {
+			e.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		} else if !c.Expires.IsZero() {
+			e.Expires = c.Expires
+		}
+
+		// Replace existing cookie and keep its creation time.
+		replaced := false
+		for i, o := range entries {
+			if o.id() == e.id() {
+				e.Created = o.Created
+				entries[i], replaced = e, true
+				break
+			}
+		}
+		if !replaced {
+			entries = append(entries, e)
+		}
+	}
+	s.setJar(entries)
+}
+
+// Cookies returns the cookies of the session's cookie jar that are sent to the given url.
+// Cookies with longer paths are listed first.
+func (s *Session) Cookies(u *url.URL) []*http.Cookie {
+	host := cookieHost(u)
+	path := u.EscapedPath()
+	if len(path) == 0 {
+		path = "/"
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+
+	now := time.Now()
+	var selected []*jarEntry
+	for _, e := range s.jar() {
+		if e.expired(now) || (e.Secure && !secure) || !pathMatch(path, e.Path) {
+			continue
+		}
+		if (e.HostOnly && host != e.Domain) || (!e.HostOnly && !domainMatch(host, e.Domain)) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		if len(selected[i].Path) != len(selected[j].Path) {
+			return len(selected[i].Path) > len(selected[j].Path)
+		}
+		return selected[i].Created.Before(selected[j].Created)
+	})
+
+	ret := make([]*http.Cookie, len(selected))
+	for i, e := range selected {
+		ret[i] = &http.Cookie{Name: e.Name, Value: e.Value}
+	}
+	return ret
+}
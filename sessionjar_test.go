@@ -0,0 +1,49 @@
+package gotojs
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func cookieNames(cs []*http.Cookie) (ret []string) {
+	for _, c := range cs {
+		ret = append(ret, c.Name+"="+c.Value)
+	}
+	return
+}
+
+func TestSessionCookieJar(t *testing.T) {
+	s := NewSession()
+	s.Set("sid", "local")
+
+	u, _ := url.Parse("http://api.example.com/v1/users")
+	s.SetCookies(u, []*http.Cookie{
+		{Name: "sid", Value: "remote"},
+		{Name: "wide", Value: "1", Domain: ".example.com", Path: "/"},
+		{Name: "deep", Value: "2", Path: "/v1/users"},
+		{Name: "secure", Value: "3", Secure: true},
+		{Name: "foreign", Value: "4", Domain: "other.com"},
+		{Name: "gone", Value: "5", MaxAge: -1}})
+
+	if s.Get("sid") != "local" {
+		t.Errorf("Remote cookie overwrote a session property.")
+	}
+
+	check := func(raw string, expected string) {
+		u, _ := url.Parse(raw)
+		if got := fmt.Sprint(cookieNames(s.Cookies(u))); got != expected {
+			t.Errorf("Unexpected cookies for %s: %s, expected %s", raw, got, expected)
+		}
+	}
+
+	check("http://api.example.com/v1/users/42", "[deep=2 sid=remote wide=1]")
+	check("https://api.example.com/v1/x", "[sid=remote secure=3 wide=1]")
+	check("http://www.example.com/v1", "[wide=1]")
+	check("http://api.example.com/v2", "[wide=1]")
+	check("http://other.com/", "[]")
+
+	s.SetCookies(u, []*http.Cookie{{Name: "wide", Value: "1", Domain: "example.com", Path: "/", MaxAge: -1}})
+	check("http://www.example.com/v1", "[]")
+}
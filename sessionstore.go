@@ -0,0 +1,289 @@
+package gotojs
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SessionStore keeps the properties of sessions on server side. If a store is set, the session
+// cookie only holds the sealed session ID.
+type SessionStore interface {
+	// Load returns the properties of the session. Nil is returned if the session is unknown or expired.
+	Load(id string) (Properties, error)
+
+	// Save stores the properties of the session until the given expiry. A zero expiry never expires.
+	Save(id string, p Properties, expires time.Time) error
+
+	// Delete removes the session.
+	Delete(id string) error
+
+	// List returns the IDs of all sessions that have not expired yet.
+	List() ([]string, error)
+
+	// Expire removes all expired sessions.
+	Expire() error
+}
+
+// newSessionID generates a random session ID.
+func newSessionID() string {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Errorf("Could not generate session id: %s", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// storedSession is a session kept by a store.
+type storedSession struct {
+	Properties Properties `json:"p"`
+	Expires    time.Time  `json:"exp"`
+}
+
+// expired returns true if the session has expired.
+func (s *storedSession) expired(now time.Time) bool {
+	return !s.Expires.IsZero() && now.After(s.Expires)
+}
+
+// copyProperties returns a copy of the given properties.
+func copyProperties(p Properties) Properties {
+	ret := make(Properties, len(p))
+	for k, v := range p {
+		ret[k] = v
+	}
+	return ret
+}
+
+// MemorySessionStore is an in-memory implementation of SessionStore.
+type MemorySessionStore struct {
+	sessions map[string]*storedSession
+	mutex    sync.RWMutex
+}
+
+// NewMemorySessionStore creates a new empty in-memory store.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]*storedSession)}
+}
+
+// Load implements the SessionStore interface.
+func (s *MemorySessionStore) Load(id string) (Properties, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if ss, found := s.sessions[id]; found && !ss.expired(time.Now()) {
+		return copyProperties(ss.Properties), nil
+	}
+	return nil, nil
+}
+
+// Save implements the SessionStore interface.
+func (s *MemorySessionStore) Save(id string, p Properties, expires time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions[id] = &storedSession{Properties: copyProperties(p), Expires: expires}
+	return nil
+}
+
+// Delete implements the SessionStore interface.
+func (s *MemorySessionStore) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// List implements the SessionStore interface.
+func (s *MemorySessionStore) List() ([]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	now := time.Now()
+	ret := make([]string, 0, len(s.sessions))
+	for id, ss := range s.sessions {
+		if !ss.expired(now) {
+			ret = append(ret, id)
+		}
+	}
+	return ret, nil
+}
+
+// Expire implements the SessionStore interface.
+func (s *MemorySessionStore) Expire() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	for id, ss := range s.sessions {
+		if ss.expired(now) {
+			delete(s.sessions, id)
+		}
+	}
+	return nil
+}
+
+// FileSessionStore is a SessionStore that keeps each session as JSON file in a directory.
+type FileSessionStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// DefaultSessionFileSuffix is the suffix of the files of a FileSessionStore.
+const DefaultSessionFileSuffix = ".session"
+
+// NewFileSessionStore creates a store in the given directory. The directory is created if
+// it does not exist.
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("Could not create session directory: %s", err)
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// path returns the file path of the given session. IDs containing path elements are rejected.
+func (s *FileSessionStore) path(id string) (string, error) {
+	if len(id) == 0 || strings.ContainsAny(id, "/\\.") {
+		return "", fmt.Errorf("Invalid session id \"%s\".", id)
+	}
+	return filepath.Join(s.dir, id+DefaultSessionFileSuffix), nil
+}
+
+// read reads a stored session from the given file.
+func (s *FileSessionStore) read(path string) (*storedSession, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var ss storedSession
+	if err := json.Unmarshal(raw, &ss); err != nil {
+		return nil, fmt.Errorf("Could not decode session \"%s\": %s", path, err)
+	}
+	return &ss, nil
+}
+
+// Load implements the SessionStore interface.
+func (s *FileSessionStore) Load(id string) (Properties, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ss, err := s.read(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if ss.expired(time.Now()) {
+		return nil, nil
+	}
+	return ss.Properties, nil
+}
+
+// Save implements the SessionStore interface.
+func (s *FileSessionStore) Save(id string, p Properties, expires time.Time) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(&storedSession{Properties: p, Expires: expires})
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return ioutil.WriteFile(path, raw, 0600)
+}
+
+// Delete implements the SessionStore interface.
+func (s *FileSessionStore) Delete(id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// each calls the given function for each stored session.
+func (s *FileSessionStore) each(f func(id, path string, ss *storedSession)) error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+DefaultSessionFileSuffix))
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		ss, err := s.read(path)
+		if err != nil {
+			log.Printf("Skipping session: %s", err)
+			continue
+		}
+		f(strings.TrimSuffix(filepath.Base(path), DefaultSessionFileSuffix), path, ss)
+	}
+	return nil
+}
+
+// List implements the SessionStore interface.
+func (s *FileSessionStore) List() (ret []string, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	err = s.each(func(id, path string, ss *storedSession) {
+		if !ss.expired(now) {
+			ret = append(ret, id)
+		}
+	})
+	return
+}
+
+// Expire implements the SessionStore interface.
+func (s *FileSessionStore) Expire() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	return s.each(func(id, path string, ss *storedSession) {
+		if ss.expired(now) {
+			os.Remove(path)
+		}
+	})
+}
+
+// SetSessionStore sets the store that keeps the session properties on server side. If nil, the
+// properties are kept in the session cookie.
+func (b *Container) SetSessionStore(s SessionStore) {
+	b.sessionStore = s
+}
+
+// SessionStore returns the store of the sessions or nil if the sessions are kept in the cookie.
+func (b *Container) SessionStore() SessionStore {
+	return b.sessionStore
+}
+
+// Sessions returns the IDs of all active sessions kept by the session store.
+func (b *Container) Sessions() ([]string, error) {
+	if b.sessionStore == nil {
+		return nil, fmt.Errorf("No session store set.")
+	}
+	return b.sessionStore.List()
+}
+
+// InvalidateSession removes the session of the given ID from the session store. Subsequent
+// calls of this session get a fresh session.
+func (b *Container) InvalidateSession(id string) error {
+	if b.sessionStore == nil {
+		return fmt.Errorf("No session store set.")
+	}
+	return b.sessionStore.Delete(id)
+}
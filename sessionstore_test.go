@@ -0,0 +1,104 @@
+package gotojs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSessionStore(t *testing.T, s SessionStore) {
+	s.Save("alive", Properties{"k": "v"}, time.Now().Add(time.Hour))
+	s.Save("dead", Properties{"k": "v"}, time.Now().Add(-time.Hour))
+
+	if p, err := s.Load("alive"); err != nil || p["k"] != "v" {
+		t.Errorf("Stored session could not be loaded: %v %s", p, err)
+	}
+
+	if p, _ := s.Load("dead"); p != nil {
+		t.Errorf("Expired session has been loaded.")
+	}
+
+	if ids, _ := s.List(); len(ids) != 1 || ids[0] != "alive" {
+		t.Errorf("Unexpected sessions listed: %v", ids)
+	}
+
+	s.Expire()
+	s.Delete("alive")
+	if p, _ := s.Load("alive"); p != nil {
+		t.Errorf("Deleted session has been loaded.")
+	}
+}
+
+func TestMemorySessionStore(t *testing.T) {
+	testSessionStore(t, NewMemorySessionStore())
+}
+
+func TestFileSessionStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gotojs-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSessionStore(t, s)
+
+	if _, err := s.Load("../passwd"); err == nil {
+		t.Errorf("Invalid session id has been accepted.")
+	}
+}
+
+func TestSessionStoreContainer(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.SetSessionStore(NewMemorySessionStore())
+	co.ExposeFunction(func(s *Session) string {
+		if v := s.Get("visits"); len(v) > 0 {
+			s.Set("visits", v+"+")
+			return s.Get("visits")
+		}
+		s.Set("visits", "1")
+		return "1"
+	}, "Counter", "Visit")
+	h := co.Setup()
+
+	call := func(c *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/gotojs/Counter/Visit", strings.NewReader("[]"))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		if c != nil {
+			req.AddCookie(c)
+		}
+		return record(h, req)
+	}
+
+	r := call(nil)
+	cookies := r.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("No session cookie set.")
+	}
+	c := cookies[0]
+
+	if s := SessionFromCookie(c, co.keys...); len(s.Properties) != 0 || len(s.id) == 0 {
+		t.Errorf("Session cookie does not only contain the session id: %v", s.Properties)
+	}
+
+	if r = call(c); r.Body.String() != `"1+"` {
+		t.Errorf("Session has not been loaded from the store: %s", r.Body.String())
+	}
+
+	ids, _ := co.Sessions()
+	if len(ids) != 1 {
+		t.Fatalf("Unexpected sessions listed: %v", ids)
+	}
+
+	co.InvalidateSession(ids[0])
+	if r = call(c); r.Body.String() != `"1"` {
+		t.Errorf("Invalidated session has been used: %s", r.Body.String())
+	}
+}
@@ -0,0 +1,81 @@
+package gotojs
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// DefaultSessionPayload is the session property that holds the registered session struct.
+const DefaultSessionPayload = "gotojs.payload"
+
+// Value decodes the JSON encoded session property of the given key into v. If the property does
+// not exist, v is left untouched.
+func (s *Session) Value(key string, v interface{}) error {
+	raw, found := s.Properties[key]
+	if !found {
+		return nil
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return fmt.Errorf("Could not decode session value \"%s\": %s", key, err)
+	}
+	return nil
+}
+
+// SetValue stores v JSON encoded as session property of the given key.
+func (s *Session) SetValue(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("Could not encode session value \"%s\": %s", key, err)
+	}
+	s.Set(key, string(raw))
+	return nil
+}
+
+// payloadOf returns the session struct of the given pointer type. It is decoded on first access
+// and the same instance is returned afterwards.
+func (s *Session) payloadOf(t reflect.Type) interface{} {
+	if s.payload != nil && reflect.TypeOf(s.payload) == t {
+		return s.payload
+	}
+
+	v := reflect.New(t.Elem()).Interface()
+	if err := s.Value(DefaultSessionPayload, v); err != nil {
+		log.Printf("%s. Using empty session struct.", err)
+		v = reflect.New(t.Elem()).Interface()
+	}
+
+	// Remember the normalized encoding in order to detect changes.
+	raw, _ := json.Marshal(v)
+	s.payload, s.payloadRaw = v, string(raw)
+	return v
+}
+
+// syncPayload stores the session struct in the session properties if it has been changed.
+func (s *Session) syncPayload() {
+	if s.payload == nil {
+		return
+	}
+	raw, err := json.Marshal(s.payload)
+	if err != nil {
+		log.Printf("Could not encode session struct: %s", err)
+		return
+	}
+	if string(raw) != s.payloadRaw {
+		s.Set(DefaultSessionPayload, string(raw))
+		s.payloadRaw = string(raw)
+	}
+}
+
+// RegisterSession registers a session struct type which is given by a pointer like &MySession{}.
+// Bindings declaring a parameter of this type get the struct of the caller's session injected.
+// The struct is stored JSON encoded in the session and written back once it has been changed.
+func (f *Container) RegisterSession(proto interface{}) {
+	t := reflect.TypeOf(proto)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("Session type must be a pointer to a struct: %s", t))
+	}
+	f.sessionType = t
+	f.SetupGlobalInjection(reflect.Zero(t).Interface())
+}
@@ -0,0 +1,76 @@
+package gotojs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type cartSession struct {
+	User  string
+	Items []string
+}
+
+func TestSessionValue(t *testing.T) {
+	s := NewSession()
+	if err := s.SetValue("cart", &cartSession{User: "joe", Items: []string{"a"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var c cartSession
+	if err := s.Value("cart", &c); err != nil || c.User != "joe" || len(c.Items) != 1 {
+		t.Errorf("Session value could not be decoded: %v %s", c, err)
+	}
+}
+
+func TestRegisterSession(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(c *cartSession, item string) int {
+		c.Items = append(c.Items, item)
+		return len(c.Items)
+	}, "Cart", "Add")
+	co.ExposeFunction(func(c *cartSession, s *Session) int {
+		return len(c.Items)
+	}, "Cart", "Count")
+	co.RegisterSession(&cartSession{})
+	h := co.Setup()
+
+	call := func(path, body string, c *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set(CTHeader, DefaultMimeType)
+		if c != nil {
+			req.AddCookie(c)
+		}
+		return record(h, req)
+	}
+
+	r := call("/gotojs/Cart/Count", "[]", nil)
+	if r.Body.String() != "0" || len(r.Result().Cookies()) != 0 {
+		t.Errorf("Unchanged session struct has been written: %s %v", r.Body.String(), r.Result().Cookies())
+	}
+
+	r = call("/gotojs/Cart/Add", `["apple"]`, nil)
+	cookies := r.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Changed session struct has not been written.")
+	}
+
+	r = call("/gotojs/Cart/Add", `["pear"]`, cookies[0])
+	if r.Body.String() != "2" {
+		t.Errorf("Session struct has not been restored: %s", r.Body.String())
+	}
+
+	var c cartSession
+	SessionFromCookie(r.Result().Cookies()[0], co.keys...).Value(DefaultSessionPayload, &c)
+	if strings.Join(c.Items, ",") != "apple,pear" {
+		t.Errorf("Unexpected session struct: %v", c)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Invalid session type has been registered.")
+		}
+	}()
+	co.RegisterSession(cartSession{})
+}
@@ -61,6 +61,11 @@ var {{.NS}} = {{.NS}} || {
 				};
 			}
 
+			{{if .CRED}}r.xhrFields = { withCredentials: true };{{end}}
+			if (http.CSRFToken && r.type != 'GET') {
+				r.headers = r.headers || {};
+				r.headers["{{.XH}}"] = http.CSRFToken;
+			}
 			r = $.ajax(r);
 			r.CRID = crid;
 
@@ -173,13 +178,38 @@ var {{.NS}} = {{.NS}} || {};
 	generateCRID: function() {
 		return {{.NS}}.CONST.CHASH + "_" + (this.callCounter++);
 	},
+	Await: function(job,callback,onprogress,interval) {
+		var proxy = this;
+		var poll = function() {
+			proxy.Call("{{.II}}","JobStatus",[job.ID, function(s) {
+				if (onprogress) {
+					onprogress(s.Progress,s);
+				}
+				switch (s.State) {
+					case "done":
+						proxy.Call("{{.II}}","JobResult",[s.ID, callback]);
+						break;
+					case "failed":
+					case "canceled":
+						throw ("Job " + s.ID + " of " + s.Binding + " " + s.State + ": " + (s.Error || ""));
+					default:
+						setTimeout(poll,interval || 500);
+				}
+			}]);
+		};
+		poll();
+		return job;
+	},
+	Cancel: function(job,callback) {
+		return this.Call("{{.II}}","JobCancel",[job.ID, callback]);
+	},
 	Post: function(url,data,callback) {
 		return {{.NS}}.HTTP.Call(this.generateCRID(),url,undefined,undefined,data,undefined,callback,"POST");
 	},
-	Call: function(i,m,args,bin,mt) {
+	Call: function(i,m,args,bin,mt,verb) {
 		var url ="{{.BC}}/"+i+"/"+m;
 		var callback = undefined;
-		var method = "POST"
+		var method = verb || "POST"
 
 		if (this.hasCallback(args)) {
 			callback = args.pop();
@@ -188,8 +218,8 @@ var {{.NS}} = {{.NS}} || {};
 		var crid = this.generateCRID();
 		var data = ""
 		if (bin !== undefined) {
-			for (var i in args) { // Encode parameters
-				args[i] = encodeURIComponent(args[i]);
+			for (var ai in args) { // Encode parameters
+				args[ai] = encodeURIComponent(args[ai]);
 			}
 
 			if (args.length > 0) {
@@ -198,6 +228,16 @@ var {{.NS}} = {{.NS}} || {};
 
 			data = bin;
 			mt = mt || "application/octet-stream";
+		} else if (method == "GET") {
+			for (var ai in args) { // Encode parameters, complex ones as JSON
+				args[ai] = encodeURIComponent((typeof args[ai] == 'string') ? args[ai] : JSON.stringify(args[ai]));
+			}
+
+			if (args.length > 0) {
+				url += "?p=" + args.join("&p=");
+			}
+			data = undefined;
+			mt = "{{.CT}}";
 		} else {
 			data = JSON.stringify(args);
 			mt = "{{.CT}}";
@@ -304,8 +344,8 @@ var {{.NS}} = {{.NS}} || {};
 }
 {{.NS}}.TYPES.INTERFACES.{{.IN}}.prototype = {
 	/* Methods */
-	Call: function(m,args,bin,mt) {
-		return this.proxy.Call(this.name,m,args,bin,mt);
+	Call: function(m,args,bin,mt,verb) {
+		return this.proxy.Call(this.name,m,args,bin,mt,verb);
 	}
 };
 {{.NS}}.{{.IN}} = new {{.NS}}.TYPES.INTERFACES.{{.IN}}()
@@ -324,9 +364,26 @@ var {{.NS}} = {{.NS}} || {};
 {{if .MA}}
 		this.proxy.assertArgs("{{.IN}}","{{.MN}}",args,"{{.AS}}");
 {{end}}
-		return this.Call("{{.MN}}",args,bin,mt);
+		return this.Call("{{.MN}}",args,bin,mt,"{{.ME}}");
 };
 
+{{if .ASY}}
+{{.NS}}.{{.IN}}.{{.MN}}.Await = function() {
+	var args = {{.NS}}.{{.IN}}.proxy.argsToArray(arguments);
+	var callback, onprogress;
+	if ({{.NS}}.{{.IN}}.proxy.hasCallback(args)) {
+		callback = args.pop();
+	}
+	if ({{.NS}}.{{.IN}}.proxy.hasCallback(args)) {
+		onprogress = callback;
+		callback = args.pop();
+	}
+	args.push(function(job) {
+		{{.NS}}.{{.IN}}.proxy.Await(job,callback,onprogress);
+	});
+	return {{.NS}}.{{.IN}}.{{.MN}}.apply({{.NS}}.{{.IN}},args);
+};
+{{end}}
 {{.NS}}.{{.IN}}.{{.MN}}.getValidationString = function() {
 	return "{{.AS}}";
 };
@@ -355,7 +412,7 @@ var {{.NS}} = {{.NS}} || {
 				callback = function(d) { console.log(d); ret.result = d;ret.state="finished";}
 			}
 			this.Request({
-				uri: this.URL + "/" + i + "/" + m,
+				uri: this.URL + "/" + i + "/" + m + (url.indexOf("?") >= 0 ? url.substring(url.indexOf("?")) : ""),
 				method: method || "POST",
 				jar: this.Jar,
 				headers: {'content-type' : imt, '{{.IH}}': crid},
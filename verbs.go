@@ -0,0 +1,82 @@
+package gotojs
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Methods declares the HTTP methods the binding may be called with. The first method is used
+// by the engine. Calls using other methods are answered with status 405. By default, any method
+// is accepted and the engine uses POST.
+func (b Binding) Methods(methods ...string) Binding {
+	bb := b.base()
+	bb.methods = make([]string, len(methods))
+	for i, m := range methods {
+		bb.methods[i] = strings.ToUpper(m)
+	}
+	bb.container.revision++
+	return b
+}
+
+// Methods declares the HTTP methods the given bindings may be called with.
+func (bs Bindings) Methods(methods ...string) Bindings {
+	for _, b := range bs {
+		b.Methods(methods...)
+	}
+	return bs
+}
+
+// Read declares the binding as read operation. It may be called via GET, which is used by the
+// engine, or POST. Read bindings are considered safe and may be cached.
+func (b Binding) Read() Binding {
+	return b.Methods("GET", "POST").Safe()
+}
+
+// Read declares the given bindings as read operations.
+func (bs Bindings) Read() Bindings {
+	for _, b := range bs {
+		b.Read()
+	}
+	return bs
+}
+
+// Write declares the binding as write operation. It may only be called via POST, PUT or DELETE.
+func (b Binding) Write() Binding {
+	return b.Methods("POST", "PUT", "DELETE")
+}
+
+// Write declares the given bindings as write operations.
+func (bs Bindings) Write() Bindings {
+	for _, b := range bs {
+		b.Write()
+	}
+	return bs
+}
+
+// AllowedMethods returns the HTTP methods the binding may be called with. If empty, any
+// method is accepted.
+func (b Binding) AllowedMethods() []string {
+	return b.base().methods
+}
+
+// httpMethod returns the HTTP method used by the engine to call the binding. Bindings
+// receiving binary content are always called via POST.
+func (b Binding) httpMethod() string {
+	if _, ok := b.bindingInterface.(*handlerBinding); ok || receivesBinaryContent(b.bindingInterface) {
+		return "POST"
+	}
+	if ms := b.base().methods; len(ms) > 0 {
+		return ms[0]
+	}
+	return "POST"
+}
+
+// checkMethod enforces the declared HTTP methods of the binding.
+func (b Binding) checkMethod(hc *HTTPContext) {
+	ms := b.base().methods
+	if len(ms) == 0 || ContainsS(ms, hc.Request.Method) {
+		return
+	}
+	hc.Response.Header().Set("Allow", strings.Join(ms, ", "))
+	hc.Errorf(http.StatusMethodNotAllowed, "Method %s not allowed for \"%s\".", hc.Request.Method, b.Name())
+}
@@ -0,0 +1,95 @@
+package gotojs
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type verbPoint struct {
+	X, Y int
+}
+
+func TestVerbs(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(p verbPoint) int { return p.X + p.Y }, "Geo", "Sum").Read()
+	co.ExposeFunction(func(p verbPoint) int { return p.X * p.Y }, "Geo", "Store").Write()
+	co.ExposeFunction(func() string { return "any" }, "Geo", "Any")
+	h := co.Setup()
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if len(body) > 0 {
+			req.Header.Set(CTHeader, DefaultMimeType)
+		}
+		return record(h, req)
+	}
+
+	if r := call("GET", "/gotojs/Geo/Sum?p="+url.QueryEscape(`{"X":1,"Y":2}`), ""); r.Code != http.StatusOK || r.Body.String() != "3" {
+		t.Errorf("Read binding could not be called via GET: %d %s", r.Code, r.Body.String())
+	}
+
+	if r := call("PUT", "/gotojs/Geo/Store", `[{"X":2,"Y":3}]`); r.Code != http.StatusOK || r.Body.String() != "6" {
+		t.Errorf("Write binding could not be called via PUT: %d %s", r.Code, r.Body.String())
+	}
+
+	r := call("GET", "/gotojs/Geo/Store?p="+url.QueryEscape(`{"X":2,"Y":3}`), "")
+	if r.Code != http.StatusMethodNotAllowed || r.Header().Get("Allow") != "POST, PUT, DELETE" {
+		t.Errorf("GET of a write binding has not been rejected: %d %s", r.Code, r.Header().Get("Allow"))
+	}
+
+	if r := call("DELETE", "/gotojs/Geo/Any", "[]"); r.Code != http.StatusOK {
+		t.Errorf("Binding without declared verbs rejected a call: %d", r.Code)
+	}
+
+	e := call("GET", "/gotojs/", "").Body.String()
+	if !strings.Contains(e, `this.Call("Sum",args,bin,mt,"GET")`) || !strings.Contains(e, `this.Call("Store",args,bin,mt,"POST")`) {
+		t.Errorf("Engine does not use the declared verbs.")
+	}
+}
+
+// runEngine executes the engine with a stubbed HTTP layer followed by the given script. It returns
+// the output of node.
+func runEngine(t *testing.T, engine, script string) string {
+	if _, err := exec.LookPath(nodeCmd); err != nil {
+		t.Skipf("Node.js not available. Skipping this test ...")
+	}
+	cmd := exec.Command(nodeCmd)
+	cmd.Stdin = strings.NewReader(engine + "\n" + script)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("Executing engine failed: %s\n%s", err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestEngineGetURL(t *testing.T) {
+	co := NewContainer(Properties{P_FLAGS: Flag2Param(F_CLEAR)})
+	co.ExposeFunction(func(a, b int) int { return a + b }, "Geo", "Sum").Read()
+	h := co.Setup()
+	engine := func(path string) string {
+		return record(h, httptest.NewRequest("GET", path, nil)).Body.String()
+	}
+
+	out := runEngine(t, engine("/gotojs/"), `
+GOTOJS.HTTP.Call = function(crid,url,i,m,data,mt,callback,method) { console.log(method + " " + url + " " + i + "." + m); };
+GOTOJS.Geo.Sum(1,2);`)
+	if out != "GET /gotojs/Geo/Sum?p=1&p=2 Geo.Sum" {
+		t.Errorf("Unexpected GET call of the engine: %s", out)
+	}
+
+	out = runEngine(t, `
+var GLOBAL = {};
+var require = function() {
+	var r = function(o) { console.log(o.method + " " + o.uri); };
+	r.jar = function() { return null; };
+	return r;
+};`+engine("/gotojs/engine.nodejs"), "GOTOJS.Geo.Sum(1,2);")
+	if !strings.HasSuffix(out, "GET http://example.com/gotojs/Geo/Sum?p=1&p=2") {
+		t.Errorf("Unexpected GET call of the nodejs engine: %s", out)
+	}
+}